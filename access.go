@@ -0,0 +1,261 @@
+package rubik
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// AccessLogFormat is the format of the lines written by the access log
+type AccessLogFormat int
+
+// AccessFormat holds the formats supported by the access log.
+// Common is the Common Log Format, Combined is Common Log Format with
+// the referer and user agent of the request and JSON writes one object
+// per request with all the information known about it
+var AccessFormat = struct {
+	Common   AccessLogFormat
+	Combined AccessLogFormat
+	JSON     AccessLogFormat
+}{1, 2, 3}
+
+// AccessLog is the configuration of the built-in access logger.
+//
+// SampleRate is the fraction of requests logged between 0 and 1, a value
+// of 0 logs every request. Responses with status 500 and above are always
+// logged. Exclude is a list of path prefixes which are never logged, for
+// example /static or your health check route.
+//
+// TrustedProxies are the IPs or CIDR ranges of your reverse proxies, the
+// X-Forwarded-For header is used for the remote IP only when the request
+// comes from one of them so that clients cannot forge their IP.
+type AccessLog struct {
+	Format         AccessLogFormat
+	SampleRate     float64
+	Exclude        []string
+	TrustedProxies []string
+	proxies        []*net.IPNet
+}
+
+// accessEntry holds everything the access log knows about a request
+type accessEntry struct {
+	Time      time.Time     `json:"time"`
+	Method    string        `json:"method"`
+	Path      string        `json:"path"`
+	Route     string        `json:"route"`
+	Proto     string        `json:"proto"`
+	Status    int           `json:"status"`
+	Bytes     int           `json:"bytes"`
+	Latency   time.Duration `json:"-"`
+	LatencyMs float64       `json:"latency_ms"`
+	RequestID string        `json:"request_id"`
+	RemoteIP  string        `json:"remote_ip"`
+	UserAgent string        `json:"user_agent"`
+	Referer   string        `json:"referer"`
+}
+
+var accessLog *AccessLog
+
+// headerRequestID is used to read and write the request id
+const headerRequestID = "X-Request-Id"
+
+// UseAccessLog enables the built-in access logger. Access log lines are
+// written to the stream configured for your service inside rubik.toml,
+// the same stream used by rubik.Log
+//
+// 		rubik.UseAccessLog(rubik.AccessLog{
+// 			Format:  rubik.AccessFormat.Combined,
+// 			Exclude: []string{"/static", "/health"},
+// 			TrustedProxies: []string{"10.0.0.0/8"},
+// 		})
+func UseAccessLog(conf AccessLog) {
+	if conf.Format == 0 {
+		conf.Format = AccessFormat.Common
+	}
+
+	conf.proxies = nil
+	for _, p := range conf.TrustedProxies {
+		ipNet, err := parseProxy(p)
+		if err != nil {
+			app.logger.Warn("Ignoring invalid trusted proxy", "proxy", p, "error", err)
+			continue
+		}
+		conf.proxies = append(conf.proxies, ipNet)
+	}
+	accessLog = &conf
+}
+
+// parseProxy parses an IP or a CIDR range into a network
+func parseProxy(p string) (*net.IPNet, error) {
+	if strings.Contains(p, "/") {
+		_, ipNet, err := net.ParseCIDR(p)
+		return ipNet, err
+	}
+
+	ip := net.ParseIP(p)
+	if ip == nil {
+		return nil, fmt.Errorf("AccessLogError: %s is not an IP or a CIDR range", p)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// maxRequestIDLength is the longest X-Request-Id accepted from a client
+const maxRequestIDLength = 64
+
+// validRequestID reports whether the id sent by a client can be used, it
+// must be short and made of letters, digits and - _ . :
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestID returns the id sent by the client in X-Request-Id header or
+// generates a new one when there is none or it is not valid
+func requestID(req *http.Request) string {
+	if id := req.Header.Get(headerRequestID); validRequestID(id) {
+		return id
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// remoteIP returns the host of the remote address of the request. When
+// the request comes from a trusted proxy the X-Forwarded-For addresses
+// are read from the right and the first one which is not a trusted proxy
+// is returned
+func (al *AccessLog) remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	if !al.trusted(host) {
+		return host
+	}
+
+	fwd := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(fwd) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(fwd[i])
+		if net.ParseIP(ip) == nil {
+			break
+		}
+		host = ip
+		if !al.trusted(ip) {
+			break
+		}
+	}
+	return host
+}
+
+// trusted reports whether the address is one of the trusted proxies
+func (al *AccessLog) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, p := range al.proxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldLog decides if the request must be written to the access log
+func (al *AccessLog) shouldLog(path string, status int) bool {
+	for _, ex := range al.Exclude {
+		if strings.HasPrefix(path, ex) {
+			return false
+		}
+	}
+
+	if status >= 500 || al.SampleRate <= 0 || al.SampleRate >= 1 {
+		return true
+	}
+	return mrand.Float64() < al.SampleRate
+}
+
+// format returns the access log line of the entry
+func (al *AccessLog) format(e accessEntry) string {
+	bytes := "-"
+	if e.Bytes > 0 {
+		bytes = fmt.Sprint(e.Bytes)
+	}
+
+	switch al.Format {
+	case AccessFormat.JSON:
+		e.LatencyMs = math.Round(float64(e.Latency)/float64(time.Microsecond)) / 1000
+		b, _ := json.Marshal(e)
+		return string(b)
+	case AccessFormat.Combined:
+		return fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s \"%s\" \"%s\"", e.RemoteIP,
+			e.Time.Format("02/Jan/2006:15:04:05 -0700"), e.Method, e.Path, e.Proto, e.Status,
+			bytes, e.Referer, e.UserAgent)
+	default:
+		return fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s", e.RemoteIP,
+			e.Time.Format("02/Jan/2006:15:04:05 -0700"), e.Method, e.Path, e.Proto, e.Status,
+			bytes)
+	}
+}
+
+// writeAccessLog writes the access log line of a completed request
+// represented by the HookContext
+func writeAccessLog(rc *HookContext, id string, start time.Time) {
+	if accessLog == nil || rc.Request == nil {
+		return
+	}
+
+	req := rc.Request
+	if !accessLog.shouldLog(req.URL.Path, rc.Status) {
+		return
+	}
+
+	line := accessLog.format(accessEntry{
+		Time:      start,
+		Method:    req.Method,
+		Path:      req.URL.RequestURI(),
+		Route:     rc.Route,
+		Proto:     req.Proto,
+		Status:    rc.Status,
+		Bytes:     rc.Size,
+		Latency:   rc.Latency,
+		RequestID: id,
+		RemoteIP:  accessLog.remoteIP(req),
+		UserAgent: req.UserAgent(),
+		Referer:   req.Referer(),
+	})
+
+	if logCh != nil {
+		logCh.stream.WriteLine(line)
+		return
+	}
+	fmt.Fprintln(os.Stdout, line)
+}
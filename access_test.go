@@ -0,0 +1,116 @@
+package rubik
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testAccessEntry() accessEntry {
+	return accessEntry{
+		Time:      time.Date(2020, 10, 10, 13, 55, 36, 0, time.UTC),
+		Method:    "GET",
+		Path:      "/users/1?full=true",
+		Route:     "/users/:id",
+		Proto:     "HTTP/1.1",
+		Status:    200,
+		Bytes:     2326,
+		Latency:   time.Millisecond * 3,
+		RequestID: "abc",
+		RemoteIP:  "127.0.0.1",
+		UserAgent: "curl/7.0",
+	}
+}
+
+func TestAccessLogCommonFormat(t *testing.T) {
+	al := AccessLog{Format: AccessFormat.Common}
+	line := al.format(testAccessEntry())
+	expected := `127.0.0.1 - - [10/Oct/2020:13:55:36 +0000] "GET /users/1?full=true HTTP/1.1" 200 2326`
+	if line != expected {
+		t.Error("access log is not in Common Log Format. Line:", line)
+	}
+}
+
+func TestAccessLogCombinedFormat(t *testing.T) {
+	al := AccessLog{Format: AccessFormat.Combined}
+	line := al.format(testAccessEntry())
+	if !strings.HasSuffix(line, `200 2326 "" "curl/7.0"`) {
+		t.Error("access log is not in Combined Log Format. Line:", line)
+	}
+}
+
+func TestAccessLogJSONFormat(t *testing.T) {
+	al := AccessLog{Format: AccessFormat.JSON}
+	line := al.format(testAccessEntry())
+	if !strings.Contains(line, `"route":"/users/:id"`) ||
+		!strings.Contains(line, `"latency_ms":3`) ||
+		!strings.Contains(line, `"request_id":"abc"`) {
+		t.Error("access log JSON does not contain the request information. Line:", line)
+	}
+}
+
+func TestAccessLogExclude(t *testing.T) {
+	al := AccessLog{Exclude: []string{"/static"}}
+	if al.shouldLog("/static/app.js", 200) {
+		t.Error("access log did not exclude the /static path")
+	}
+
+	al.SampleRate = 0.0001
+	if !al.shouldLog("/users", 500) {
+		t.Error("access log sampled out a server error")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	if requestID(req) == "" {
+		t.Error("requestID() did not generate an id")
+	}
+
+	req.Header.Set(headerRequestID, "client-id")
+	if requestID(req) != "client-id" {
+		t.Error("requestID() did not use the X-Request-Id sent by the client")
+	}
+
+	for _, id := range []string{strings.Repeat("a", maxRequestIDLength+1), "id\nforged line",
+		"id with spaces"} {
+		req.Header.Set(headerRequestID, id)
+		if got := requestID(req); got == id || got == "" {
+			t.Errorf("requestID() = %q for the invalid id %q, want a generated id", got, id)
+		}
+	}
+}
+
+func TestRemoteIP(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.5:4000"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	al := &AccessLog{}
+	if ip := al.remoteIP(req); ip != "10.0.0.5" {
+		t.Errorf("remoteIP() = %s without trusted proxies, want 10.0.0.5", ip)
+	}
+
+	for _, p := range []string{"10.0.0.0/8", "192.168.1.1"} {
+		ipNet, err := parseProxy(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		al.proxies = append(al.proxies, ipNet)
+	}
+	if _, err := parseProxy("proxy"); err == nil {
+		t.Error("parseProxy() did not fail for an invalid proxy")
+	}
+
+	req.Header.Set("X-Forwarded-For", "9.9.9.9, 1.2.3.4, 192.168.1.1")
+	if ip := al.remoteIP(req); ip != "1.2.3.4" {
+		t.Errorf("remoteIP() = %s behind trusted proxies, want 1.2.3.4", ip)
+	}
+
+	req.RemoteAddr = "1.2.3.4:4000"
+	req.Header.Set("X-Forwarded-For", "9.9.9.9")
+	if ip := al.remoteIP(req); ip != "1.2.3.4" {
+		t.Errorf("remoteIP() = %s from an untrusted client, want 1.2.3.4", ip)
+	}
+}
@@ -0,0 +1,208 @@
+package rubik
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rubikorg/blocks/ds"
+)
+
+// ConfigView gives typed access to the config of your app using dotted
+// keys. Every getter returns false when the key is not present or its
+// value cannot be converted to the type, the Or variants return the
+// default value instead:
+//
+// 		db := req.ConfigView().Sub("database")
+// 		host := db.StringOr("host", "localhost")
+// 		timeout, ok := db.Duration("timeout")
+//
+// The view always reads the current config so it sees the new values
+// after the config is reloaded
+type ConfigView struct {
+	prefix string
+	source func() ds.NotationMap
+}
+
+// GetConfigView returns the ConfigView of the config loaded by Load
+func GetConfigView() ConfigView {
+	return ConfigView{source: app.notationMap}
+}
+
+// Sub returns the view of the table at the dotted prefix
+func (cv ConfigView) Sub(prefix string) ConfigView {
+	return ConfigView{prefix: cv.key(prefix) + ".", source: cv.source}
+}
+
+func (cv ConfigView) key(key string) string {
+	return cv.prefix + strings.Trim(key, ".")
+}
+
+// Get returns the raw value of the key
+func (cv ConfigView) Get(key string) (interface{}, bool) {
+	if cv.source == nil {
+		return nil, false
+	}
+
+	val := cv.source().Get(cv.key(key))
+	return val, val != nil
+}
+
+// Has returns true if the key is present in the config
+func (cv ConfigView) Has(key string) bool {
+	_, ok := cv.Get(key)
+	return ok
+}
+
+// String returns the string value of the key, numbers and booleans are
+// formatted as strings
+func (cv ConfigView) String(key string) (string, bool) {
+	val, ok := cv.Get(key)
+	if !ok {
+		return "", false
+	}
+
+	switch v := val.(type) {
+	case string:
+		return v, true
+	case int64, int, float64, bool:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// StringOr returns the string value of the key or def
+func (cv ConfigView) StringOr(key, def string) string {
+	if s, ok := cv.String(key); ok {
+		return s
+	}
+	return def
+}
+
+// Int returns the integer value of the key, strings are parsed as
+// base 10 integers
+func (cv ConfigView) Int(key string) (int, bool) {
+	val, ok := cv.Get(key)
+	if !ok {
+		return 0, false
+	}
+
+	switch v := val.(type) {
+	case int64:
+		return int(v), true
+	case int:
+		return v, true
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), true
+		}
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// IntOr returns the integer value of the key or def
+func (cv ConfigView) IntOr(key string, def int) int {
+	if i, ok := cv.Int(key); ok {
+		return i
+	}
+	return def
+}
+
+// Bool returns the boolean value of the key, strings are parsed using
+// strconv.ParseBool
+func (cv ConfigView) Bool(key string) (bool, bool) {
+	val, ok := cv.Get(key)
+	if !ok {
+		return false, false
+	}
+
+	switch v := val.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return b, err == nil
+	}
+	return false, false
+}
+
+// BoolOr returns the boolean value of the key or def
+func (cv ConfigView) BoolOr(key string, def bool) bool {
+	if b, ok := cv.Bool(key); ok {
+		return b
+	}
+	return def
+}
+
+// Duration returns the duration value of the key. Strings are parsed
+// using time.ParseDuration (ex: "1m30s") and numbers are seconds
+func (cv ConfigView) Duration(key string) (time.Duration, bool) {
+	val, ok := cv.Get(key)
+	if !ok {
+		return 0, false
+	}
+
+	switch v := val.(type) {
+	case string:
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		return d, err == nil
+	case int64:
+		return time.Duration(v) * time.Second, true
+	case int:
+		return time.Duration(v) * time.Second, true
+	case float64:
+		return time.Duration(v * float64(time.Second)), true
+	}
+	return 0, false
+}
+
+// DurationOr returns the duration value of the key or def
+func (cv ConfigView) DurationOr(key string, def time.Duration) time.Duration {
+	if d, ok := cv.Duration(key); ok {
+		return d
+	}
+	return def
+}
+
+// StringSlice returns the array value of the key as strings, a string
+// value is split by commas
+func (cv ConfigView) StringSlice(key string) ([]string, bool) {
+	val, ok := cv.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	switch v := val.(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		s := make([]string, 0, len(v))
+		for _, x := range v {
+			str, ok := x.(string)
+			if !ok {
+				return nil, false
+			}
+			s = append(s, str)
+		}
+		return s, true
+	case string:
+		parts := strings.Split(v, ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		return parts, true
+	}
+	return nil, false
+}
+
+// StringSliceOr returns the array value of the key as strings or def
+func (cv ConfigView) StringSliceOr(key string, def []string) []string {
+	if s, ok := cv.StringSlice(key); ok {
+		return s
+	}
+	return def
+}
@@ -0,0 +1,77 @@
+package rubik
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rubikorg/blocks/ds"
+)
+
+func testConfigView() ConfigView {
+	nm := ds.NewNotationMap()
+	nm.Assign(map[string]interface{}{
+		"name":  "rubik",
+		"debug": "true",
+		"database": map[string]interface{}{
+			"port":    int64(5432),
+			"timeout": "1m30s",
+			"retry":   int64(2),
+			"hosts":   []interface{}{"a", "b"},
+		},
+		"origins": "x.com, y.com",
+	})
+	nm.Flatten()
+	return ConfigView{source: func() ds.NotationMap { return nm }}
+}
+
+func TestConfigView(t *testing.T) {
+	cv := testConfigView()
+
+	if s, ok := cv.String("name"); !ok || s != "rubik" {
+		t.Error("String() returned", s, ok)
+	}
+
+	if b, ok := cv.Bool("debug"); !ok || !b {
+		t.Error("Bool() did not parse string value", b, ok)
+	}
+
+	db := cv.Sub("database")
+	if p, ok := db.Int("port"); !ok || p != 5432 {
+		t.Error("Sub().Int() returned", p, ok)
+	}
+
+	if d, ok := db.Duration("timeout"); !ok || d != 90*time.Second {
+		t.Error("Duration() did not parse string value", d, ok)
+	}
+
+	if d, _ := db.Duration("retry"); d != 2*time.Second {
+		t.Error("Duration() did not use seconds for numbers", d)
+	}
+
+	if hosts, ok := db.StringSlice("hosts"); !ok || strings.Join(hosts, ",") != "a,b" {
+		t.Error("StringSlice() returned", hosts, ok)
+	}
+
+	if o, _ := cv.StringSlice("origins"); len(o) != 2 || o[1] != "y.com" {
+		t.Error("StringSlice() did not split string value", o)
+	}
+}
+
+func TestConfigViewDefaults(t *testing.T) {
+	cv := testConfigView()
+
+	if _, ok := cv.Int("name"); ok {
+		t.Error("Int() returned ok for a non numeric value")
+	}
+
+	if cv.IntOr("missing", 7) != 7 || cv.StringOr("database.missing", "x") != "x" ||
+		cv.DurationOr("missing", time.Second) != time.Second || !cv.BoolOr("missing", true) {
+		t.Error("Or getters did not return the default value")
+	}
+
+	var empty ConfigView
+	if empty.Has("name") {
+		t.Error("Has() returned true for an empty ConfigView")
+	}
+}
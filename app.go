@@ -35,31 +35,30 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
 	"reflect"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/julienschmidt/httprouter"
 
-	"github.com/BurntSushi/toml"
-
 	"github.com/rubikorg/blocks/ds"
 	"github.com/rubikorg/rubik/pkg"
 )
 
 // App is a singleton instance of rubik server
 var app = &rubik{
-	mux:     httprouter.New(),
-	routers: []Router{},
-	logger: &pkg.Logger{
-		CanLog: true,
-	},
+	mux:         httprouter.New(),
+	routers:     []Router{},
+	logger:      pkg.DefaultLogger,
 	blocks:      make(map[string]Block),
 	afterBlocks: make(map[string]Block),
+	blockOpts:   make(map[string]BlockOptions),
+	failed:      make(map[string]error),
 	routeTree: RouteTree{
 		RouterList: make(map[string]string),
 		Routes:     []RouteInfo{},
@@ -72,24 +71,33 @@ var beforeHooks []RequestHook
 var afterHooks []RequestHook
 
 // Log is a collection of channels of strings which are used to
-// stream logs into a folder called logs, where "E" channel
-// writes to $app.rubik.error.log and "I" channel writes to
-// $app.rubik.info.log file inside the logs/ folder
+// stream logs into the stream configured inside the [app.logging]
+// table of your service in rubik.toml. For the file stream the "E"
+// channel writes to $app.rubik.error.log and the other channels
+// write to $app.rubik.info.log file inside the logs/ folder, unless
+// path and err_path are given. Stdout is used when nothing is
+// configured.
+//
+// The channels are drained once the server boots and the pending
+// messages are flushed when the server shuts down.
 var Log = struct {
 	E chan string
 	I chan string
 	D chan string
 	W chan string
 }{
-	E: make(chan string),
-	I: make(chan string),
-	D: make(chan string),
-	W: make(chan string),
+	E: make(chan string, logBufferSize),
+	I: make(chan string, logBufferSize),
+	D: make(chan string, logBufferSize),
+	W: make(chan string, logBufferSize),
 }
 
 const (
 	// Version of rubik
 	Version = "0.3.0"
+	// logBufferSize is the number of messages a Log channel holds
+	// before the sender is blocked
+	logBufferSize = 256
 )
 
 type tracer interface {
@@ -113,25 +121,40 @@ type Controller func(*Request)
 
 // Request ...
 type Request struct {
-	app     *rubik
-	Entity  interface{}
-	Session SessionManager
-	Writer  RResponseWriter
-	Params  httprouter.Params
-	Raw     *http.Request
-	Ctx     context.Context
-	Claims  Claims
+	app       *rubik
+	Entity    interface{}
+	Session   SessionManager
+	Writer    RResponseWriter
+	Params    httprouter.Params
+	Raw       *http.Request
+	Ctx       context.Context
+	Claims    Claims
+	id        string
+	err       error
+	templates []string
 }
 
 // Claims populates the JWT.MapClaims interface
 type Claims interface{}
 
-// HookContext ...
+// HookContext is the information about a completed request which is
+// passed to the request hooks.
+//
+// Route is the pattern of the matched route (ex: /users/:id) and Error
+// is the error passed to Request.Throw or the error returned while
+// injecting the Entity, if any.
 type HookContext struct {
-	Request  *http.Request
-	Ctx      map[string]interface{}
-	Response []byte
-	Status   int
+	Request   *http.Request
+	Ctx       map[string]interface{}
+	Response  []byte
+	Status    int
+	Size      int
+	Header    http.Header
+	Route     string
+	Entity    interface{}
+	Error     error
+	Latency   time.Duration
+	RequestID string
 }
 
 // RequestHook ...
@@ -140,6 +163,9 @@ type RequestHook func(*HookContext)
 // Rubik is the instance of Server which holds all the necessary information of apis
 type rubik struct {
 	config         interface{}
+	configMap      map[string]interface{}
+	configType     reflect.Type
+	secretKeys     []string
 	intermConfig   ds.NotationMap
 	logger         *pkg.Logger
 	currentEnv     string
@@ -147,6 +173,8 @@ type rubik struct {
 	mux            *httprouter.Router
 	blocks         map[string]Block
 	afterBlocks    map[string]Block
+	blockOpts      map[string]BlockOptions
+	failed         map[string]error
 	routers        []Router
 	routeTree      RouteTree
 	extensions     []Plugin
@@ -158,13 +186,43 @@ func (req Request) GetRouteTree() RouteTree {
 	return req.app.routeTree
 }
 
-// Config returns the configuration of your server  for a specific accessor
+// RequestID returns the id of this request. It is the value of the
+// X-Request-Id header sent by the client or an id generated by rubik,
+// the same id is written in the X-Request-Id header of the response
+func (req *Request) RequestID() string {
+	return req.id
+}
+
+// Logger returns the structured logger of rubik with the method and path
+// of this request added as fields to every message
+func (req *Request) Logger() *pkg.Logger {
+	logger := pkg.DefaultLogger
+	if req.app != nil && req.app.logger != nil {
+		logger = req.app.logger
+	}
+
+	if req.Raw == nil {
+		return logger
+	}
+	return logger.With("method", req.Raw.Method, "path", req.Raw.URL.Path,
+		"request_id", req.id)
+}
+
+// ConfigView returns the typed accessors of the config of your server
+func (req Request) ConfigView() ConfigView {
+	if req.app == nil {
+		return ConfigView{}
+	}
+	return ConfigView{source: req.app.notationMap}
+}
+
+// Config returns the configuration of your server for a specific dotted
+// accessor (ex: database.host), use ConfigView for typed values
 func (req Request) Config(accessor string) interface{} {
-	val := req.app.intermConfig.Get(accessor)
+	val := req.app.notationMap().Get(accessor)
 	if val == nil {
-		msg := fmt.Sprintf("MiddlewareAccessorError: cannot access %s from "+
-			"project config", accessor)
-		pkg.ErrorMsg(msg)
+		req.app.logger.Error("MiddlewareAccessorError: cannot access accessor from project config",
+			"accessor", accessor)
 
 		return nil
 	}
@@ -180,11 +238,17 @@ func (req Request) Config(accessor string) interface{} {
 //
 // [ Entity check --- Guard() --- Validation() --- []Middlewares()
 // --- Controller() ]
+//
+// ResponseDeclarations describes the status codes of the route and
+// ResponseTypes holds a value of the type sent with a status code,
+// both are used to document your API. Export marks the routes which
+// are part of your public API.
 type Route struct {
 	Path                 string
 	Method               string
 	Description          string
 	ResponseDeclarations map[int]string
+	ResponseTypes        map[int]interface{}
 	JSON                 bool
 	Export               bool
 	Entity               interface{}
@@ -203,47 +267,69 @@ type RouteTree struct {
 
 // RouteInfo is a flat structure for processing information about the routes
 type RouteInfo struct {
-	FullPath    string
-	Path        string
-	Description string
-	BelongsTo   string
-	Entity      interface{}
-	IsJSON      bool
-	Method      string
-	Responses   map[int]string
+	FullPath      string
+	Path          string
+	Description   string
+	BelongsTo     string
+	Entity        interface{}
+	IsJSON        bool
+	Export        bool
+	Method        string
+	Responses     map[int]string
+	ResponseTypes map[int]interface{}
+}
+
+// SetLogger replaces the structured logger used by rubik, the requests and
+// the blocks. Use pkg.NewLogger to create a logger with your own output,
+// format and minimum level
+func SetLogger(logger *pkg.Logger) {
+	app.logger = logger
 }
 
 // GetConfig returns the injected config from the Load method
 func GetConfig() interface{} {
+	configMu.RLock()
+	defer configMu.RUnlock()
 	return app.config
 }
 
-// Attach a block to rubik tree
-func Attach(symbol string, b Block) {
+// Attach a block to rubik tree. The BlockOptions are optional and control
+// how the block is attached on boot:
+//
+// 		rubik.Attach("analytics", analytics, rubik.BlockOptions{
+// 			Optional: true,
+// 			Timeout:  time.Second * 5,
+// 			Retries:  3,
+// 		})
+func Attach(symbol string, b Block, opts ...BlockOptions) {
 	name := strings.ToLower(symbol)
 	if app.blocks[name] != nil {
-		msg := fmt.Sprintf("Block %s will not be attached on boot as symbol: %s exists",
-			symbol, name)
-		pkg.ErrorMsg(msg)
+		app.logger.Error("Block will not be attached on boot as symbol exists",
+			"block", symbol, "symbol", name)
 
 		return
 	}
 
 	app.blocks[name] = b
+	if len(opts) > 0 {
+		app.blockOpts[name] = opts[0]
+	}
 }
 
 // AttachAfter attaches blocks after boot sequence of routes are complete
-func AttachAfter(symbol string, b Block) {
+func AttachAfter(symbol string, b Block, opts ...BlockOptions) {
 	name := strings.ToLower(symbol)
 	if app.afterBlocks[name] != nil {
-		msg := fmt.Sprintf("Block %s will not be attached on boot as symbol: %s exists",
-			symbol, name)
-		pkg.ErrorMsg(msg)
+		app.logger.Error("Block will not be attached on boot as symbol exists",
+			"block", symbol, "symbol", name)
 
 		return
 	}
 
 	app.afterBlocks[name] = b
+	if len(opts) > 0 {
+		app.blockOpts[name] = opts[0]
+	}
 }
 
 // GetBlock returns the block that is attached to rubik represented by the
@@ -252,26 +338,130 @@ func GetBlock(symbol string) Block {
 	return app.blocks[strings.ToLower(symbol)]
 }
 
-// Plug adds an extension of Rubik to your workflow
+// LookupBlock assigns the block attached by the symbol to the variable
+// pointed by target. It returns an error if there is no such block or
+// the block cannot be assigned to the type of the variable
+//
+// 		var db *DBBlock
+// 		err := rubik.LookupBlock("db", &db)
+func LookupBlock(symbol string, target interface{}) error {
+	tv := reflect.ValueOf(target)
+	if tv.Kind() != reflect.Ptr || tv.IsNil() {
+		return fmt.Errorf("NonPointerValueError: LookupBlock() requires a pointer "+
+			"variable for block %s", symbol)
+	}
+
+	name := strings.ToLower(symbol)
+	b := app.blocks[name]
+	if b == nil {
+		b = app.afterBlocks[name]
+	}
+	if err := app.failed[name]; err != nil {
+		return fmt.Errorf("BlockNotAttachedError: optional block %s failed to attach: %v",
+			symbol, err)
+	}
+	if b == nil {
+		return fmt.Errorf("BlockNotFoundError: no block is attached by the symbol %s", symbol)
+	}
+
+	bv := reflect.ValueOf(b)
+	if !bv.Type().AssignableTo(tv.Elem().Type()) {
+		return fmt.Errorf("BlockTypeError: block %s is of type %s and cannot be "+
+			"assigned to %s", symbol, bv.Type(), tv.Elem().Type())
+	}
+
+	tv.Elem().Set(bv)
+	return nil
+}
+
+// blockInjection is a variable to which a block is assigned on boot
+type blockInjection struct {
+	symbol string
+	target interface{}
+}
+
+var blockInjections []blockInjection
+
+// InjectBlock assigns the block attached by the symbol to the variable
+// pointed by target after all the blocks are attached. The boot fails
+// with a BootError if the block is missing or is of another type, so
+// a typo in the symbol is found before the server starts
+//
+// 		var db *DBBlock
+//
+// 		func init() {
+// 			rubik.InjectBlock("db", &db)
+// 		}
+func InjectBlock(symbol string, target interface{}) {
+	blockInjections = append(blockInjections, blockInjection{symbol, target})
+}
+
+// bootInjections assigns the blocks requested using InjectBlock
+func bootInjections() error {
+	for _, bi := range blockInjections {
+		if err := LookupBlock(bi.symbol, bi.target); err != nil {
+			return BootError{Block: strings.ToLower(bi.symbol), Err: err}
+		}
+	}
+	return nil
+}
+
+// Plug adds an extension of Rubik to your workflow. The RunID of every
+// plugin must be unique, running a plugin fails if two plugins use
+// the same RunID
 func Plug(ext Plugin) {
+	for _, p := range app.extensions {
+		if p.RunID() == ext.RunID() {
+			app.logger.Error("Plugin will not be plugged as RunID exists",
+				"plugin", ext.Name(), "run_id", ext.RunID(), "plugged", p.Name())
+			duplicatePlugins = append(duplicatePlugins, ext.RunID())
+			return
+		}
+	}
+
 	app.extensions = append(app.extensions, ext)
 }
 
-// BeforeRequest is used to execute the request hook h. When a request is sent on a certain route
-// the hook specified as h is executed in a separate goroutine without hindering the current
-// main goroutine of request.
+// BeforeRequest is used to execute the request hook h before the middlewares of
+// a route. The hook is executed synchronously in the request goroutine right
+// after the entity is injected, but it only has access to the *http.Request and
+// cannot stop the request. Use UseHook with Phase.AfterInjection if your hook
+// needs access to the Request or wants to respond early.
 func BeforeRequest(h RequestHook) {
 	beforeHooks = append(beforeHooks, h)
 }
 
 // AfterRequest is used to execute the request hook h after completion of the request. A
 // request is said to be complete only after the response is written through http.ResponseWriter
-// interface of http.Server.
+// interface of http.Server. After hooks are fire-and-forget, they are delivered by a
+// bounded pool of workers which can be configured using SetAfterHookPool.
 func AfterRequest(h RequestHook) {
 	afterHooks = append(afterHooks, h)
 }
 
-// Load method loads the config/RUBIK_ENV.toml file into the interface given
+// ConfigEnvPrefix is the prefix of the environment variables which override
+// the keys of your config. Double underscore separates the tables of the key,
+// RUBIK_CFG_DATABASE__HOST overrides the host key of the [database] table
+var ConfigEnvPrefix = "RUBIK_CFG_"
+
+// Load method loads the config/RUBIK_ENV.toml file into the interface given.
+// The RUBIK_ENV config is merged over config/default.toml, the files can also
+// be JSON, YAML or dotenv files and can be read from another directory or
+// an embed.FS using SetConfigDir and UseConfigSource. After merging, the
+// keys are overridden by the ConfigEnvPrefix environment variables and then
+// by the overrides given with OverrideConfig or the `-set key=value` flags
+// registered by ConfigSetFlag:
+//
+// 		RUBIK_CFG_DATABASE__HOST=db.prod ./server -set database.port=5433
+//
+// Values can point to secrets using "${file:/run/secrets/db_password}" or
+// "${env:DB_PASSWORD}" which are resolved before validating and decoding,
+// use RedactedConfig to print your config without the resolved secrets.
+//
+// When config is a struct the merged config is validated against it before
+// decoding, see pkg.ConfigSchema for the validation rules supported by the
+// config tag and StrictConfig for reporting unknown keys. All the problems
+// are returned together as a pkg.ConfigError.
 func Load(config interface{}) error {
 	configKind := reflect.ValueOf(config).Kind()
 	if configKind != reflect.Ptr {
@@ -287,10 +477,6 @@ func Load(config interface{}) error {
 	// }
 	// app.wsConfig = wsConfig
 
-	var defaultMap map[string]interface{}
-	var envMap map[string]interface{}
-	var envConfigPath string
-
 	// set the current env to app.currentEnv
 	env := os.Getenv("RUBIK_ENV")
 	app.currentEnv = env
@@ -302,69 +488,36 @@ func Load(config interface{}) error {
 		app.currentEnv = "default"
 	}
 
-	defaultConfigPath := filepath.Join(".", "config", app.currentEnv+".toml")
-	envConfigFound := false
-
-	if env != "" && env != "plugin" {
-		envConfigPath = filepath.Join(".", "config", app.currentEnv+".toml")
-
-		if _, err := os.Stat(envConfigPath); os.IsNotExist(err) {
-			// do this with logger
-			msg := fmt.Sprintf("ConfigNotFound: config file %s does not exist",
-				envConfigPath)
-			pkg.DebugMsg(msg)
-		} else {
-			envConfigFound = true
-		}
+	values, origins, found, err := mergeConfig(app.currentEnv, reflect.TypeOf(config).Elem())
+	if err != nil {
+		return err
 	}
 
-	app.intermConfig = ds.NewNotationMap()
-
-	if !envConfigFound {
+	if !found {
 		// if no config files are there inside the config directory we cannot load
 		// any config inside the rubik app. so we don't have to error the user
 		// giving them the freedom to use rubik without the core feature
-		if _, err := os.Stat(defaultConfigPath); os.IsNotExist(err) {
-			return nil
-		}
-
-		_, err := toml.DecodeFile(defaultConfigPath, config)
-		// you can use envMap here since there is no env found and that assignment
-		// is not going anywhere until this scope ends we make use of the resources
-		_, err = toml.DecodeFile(defaultConfigPath, &envMap)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-
-		app.intermConfig.Assign(envMap)
-	} else {
-		// now we need to override env config values with the default values
-		_, err := toml.DecodeFile(defaultConfigPath, &defaultMap)
-		_, err = toml.DecodeFile(envConfigPath, &envMap)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-
-		finalMap := pkg.OverrideValues(defaultMap, envMap)
-		var buf bytes.Buffer
-		enc := toml.NewEncoder(&buf)
-		err = enc.Encode(&finalMap)
-		if err != nil {
-			return errors.WithStack(err)
-		}
+		app.intermConfig = ds.NewNotationMap()
+		return nil
+	}
 
-		err = toml.Unmarshal(buf.Bytes(), config)
-		app.intermConfig.Assign(finalMap)
+	secrets, err := pkg.ResolveSecrets(values)
+	if err != nil {
+		return err
 	}
 
-	// irrespective of env found or not flatten the intermConfig
-	if app.intermConfig.Length() > 0 {
-		app.intermConfig.Flatten()
+	nm, err := decodeConfig(config, values, origins, secrets)
+	if err != nil {
+		return err
 	}
 
+	configMu.Lock()
+	defer configMu.Unlock()
+	app.intermConfig = nm
+	app.configMap = values
+	app.configType = reflect.TypeOf(config).Elem()
+	app.secretKeys = secrets
 	app.config = reflect.ValueOf(config).Elem().Interface()
-	// before loading anything to interm config mark notation map as not editable
-	app.intermConfig.IsEditable(false)
 
 	// run on host and port mentioned inside the config
 	app.url = fmt.Sprintf("%v:%v", app.intermConfig.Get("host"), app.intermConfig.Get("port"))
@@ -472,6 +625,10 @@ func SetNotFoundHandler(h http.Handler) {
 // message passing channels and port resolution; before starting the server.
 // If this method does not find PORT that is passed as the first argument or the
 // config/*RUBIK_ENV.toml then it starts at :8000.
+//
+// With RUBIK_ENV=plugin the plugin is run instead of the server, a failed
+// plugin exits the process with its exit code and -h returns nil after
+// printing the usage of the plugin.
 func Run(serviceIdent string) error {
 	app.currentService = serviceIdent
 
@@ -486,11 +643,7 @@ func Run(serviceIdent string) error {
 	// if you are in extentions mode run only extensions and exit
 	// do not run the server
 	if env != "" && strings.ToLower(env) == "plugin" {
-		err = boot(false, true)
-		if err != nil {
-			return err
-		}
-		return nil
+		return pluginExit(boot(false, true))
 	}
 
 	err = boot(false, false)
@@ -512,16 +665,66 @@ func Run(serviceIdent string) error {
 	} else {
 		tomlUsed = env
 	}
-	fmt.Println("\n\nStarted development server on: " + app.url)
-	fmt.Printf("Rubik version %s, configured from \"%s.toml\"\n", Version, tomlUsed)
+	app.logger.Info("Started development server", "url", app.url, "version", Version,
+		"config", tomlUsed+".toml")
 
-	return http.ListenAndServe(app.url, app.mux)
+	if configWatchInterval > 0 {
+		stop := make(chan struct{})
+		defer close(stop)
+		go watchConfig(configWatchInterval, stop)
+	}
+
+	return serve(&http.Server{Addr: app.url, Handler: app})
+}
+
+// serve runs the server until it receives an interrupt or terminate
+// signal, after which it shuts down the server gracefully and flushes
+// the logs
+func serve(srv *http.Server) error {
+	defer flushLogChannel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-sigChan:
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	}
 }
 
 // Respond is a terminal function for rubik controller that sends byte response
 // it wraps around your arguments for better reading
+//
+// With Type.Auto the format is chosen from the Accept header of the request
+// among the encoders registered with RegisterEncoder: JSON, XML, plain text,
+// HTML rendered by the template bound with BindTemplate and msgpack. The
+// request fails with 406 Not Acceptable when no accepted format can encode
+// the data
+//
+// Type.Bytes writes []byte, string or io.Reader data as it is and Type.Gob
+// encodes the data with encoding/gob. Respond always writes status 200, use
+// Status to respond with another status and headers
 func (req *Request) Respond(data interface{}, ofType ...ByteType) {
-	ty := defByteType(ofType)
+	req.respond(200, data, defByteType(ofType))
+}
+
+// respond writes the data with the status, the responses which cannot
+// have a body like 204 only write the status
+func (req *Request) respond(status int, data interface{}, ty ByteType) {
+	if !bodyAllowed(status) {
+		req.Writer.WriteHeader(status)
+		return
+	}
 
 	switch ty {
 	case Type.HTML:
@@ -530,7 +733,7 @@ func (req *Request) Respond(data interface{}, ofType ...ByteType) {
 			req.Throw(500, E("Error: cannot be written as HTML"))
 			return
 		}
-		writeResponse(&req.Writer, 200, Content.HTML, []byte(s))
+		req.writeBody(status, Content.HTML, []byte(s))
 		break
 	case Type.Text:
 		s, ok := data.(string)
@@ -538,15 +741,28 @@ func (req *Request) Respond(data interface{}, ofType ...ByteType) {
 			req.Throw(500, E("Error: cannot be written as Text"))
 			return
 		}
-		writeResponse(&req.Writer, 200, Content.Text, []byte(s))
+		req.writeBody(status, Content.Text, []byte(s))
 		break
 	case Type.JSON:
-		req.Writer.Header().Add(Content.Header, Content.JSON)
-		req.Writer.WriteHeader(200)
-		err := json.NewEncoder(&req.Writer).Encode(data)
+		// encoded before writing the status so that an error can still be
+		// sent as a 500 response
+		var buf bytes.Buffer
+		err := json.NewEncoder(&buf).Encode(data)
 		if err != nil {
 			req.Throw(500, err)
+			return
 		}
+		req.writeBody(status, Content.JSON, buf.Bytes())
+	case Type.Bytes:
+		req.respondBytes(status, data)
+	case Type.Gob:
+		req.respondWith(status, Content.Gob, data)
+	case Type.XML:
+		req.respondWith(status, Content.XML, data)
+	case Type.MsgPack:
+		req.respondWith(status, Content.MsgPack, data)
+	case Type.Auto:
+		req.negotiate(status, data)
 	}
 }
 
@@ -558,13 +774,14 @@ func (req *Request) Respond(data interface{}, ofType ...ByteType) {
 // you can use rubik.E() to quickly wrap your string into an error
 // and pass it inside this function
 func (req *Request) Throw(status int, err error, btype ...ByteType) {
+	req.err = err
 	ty := defByteType(btype)
 	switch ty {
 	case Type.Text:
 		writeResponse(&req.Writer, status, Content.Text, []byte(err.Error()))
 		break
 	case Type.JSON:
-		req.Writer.Header().Add(Content.Header, Content.JSON)
+		req.Writer.Header().Set(Content.Header, Content.JSON)
 		req.Writer.WriteHeader(status)
 		jsonErr := RestErrorMixin{status, err.Error()}
 		json.NewEncoder(&req.Writer).Encode(&jsonErr)
@@ -599,14 +816,14 @@ func runRepl() {
 	if mode != "" && mode == "repl" {
 		err := boot(true, false)
 		if err != nil {
-			pkg.ErrorMsg("Error while booting: " + err.Error())
+			app.logger.Error("Error while booting", "error", err)
 		}
 
 		// do not run repl if it is not a rubik project
 		// it is a rubik project if the pwd contains rubik.toml
 		projPath := pkg.GetRubikConfigPath()
 		if _, err := os.Stat(projPath); os.IsNotExist(err) {
-			pkg.ErrorMsg("Not a rubik project!")
+			app.logger.Error("Not a rubik project!")
 		}
 
 		repl()
@@ -1,11 +1,17 @@
 package rubik
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/rubikorg/blocks/ds"
+	"github.com/rubikorg/rubik/pkg"
 )
 
 // Block is an interface that can be implemented to provide
@@ -25,6 +31,218 @@ type Block interface {
 	OnAttach(*App) error
 }
 
+// BlockOptions controls how a block is attached on boot.
+//
+// A block is required by default and the boot fails if it cannot be
+// attached. An Optional block which fails is logged, reported by the
+// /rubik/health endpoint and removed from the attached blocks. Timeout
+// limits the time taken by OnAttach and Retries is the number of times
+// OnAttach is called again after failing, waiting Backoff before the
+// first retry and doubling it after every retry. A block which times out
+// is retried too, App.Context is cancelled at the timeout so that
+// OnAttach can stop its work and every attempt gets its own context.
+type BlockOptions struct {
+	Optional bool
+	Timeout  time.Duration
+	Retries  int
+	Backoff  time.Duration
+}
+
+// defaultBackoff is the wait before the first retry of a block
+const defaultBackoff = time.Millisecond * 500
+
+// attach calls OnAttach of the block as specified by the options
+func (opts BlockOptions) attach(b Block, sb *App) error {
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+
+	var err error
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		err = opts.attachOnce(b, sb)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// errAttachTimeout is returned by attachOnce when OnAttach did not return
+// within the timeout
+var errAttachTimeout = errors.New("BlockTimeoutError: OnAttach did not return within the timeout")
+
+// attachOnce calls OnAttach once within the timeout. Every attempt gets a
+// copy of the App with its own context which is cancelled at the timeout,
+// the result of an OnAttach which returns after it is ignored
+func (opts BlockOptions) attachOnce(b Block, sb *App) error {
+	if opts.Timeout <= 0 {
+		return b.OnAttach(sb)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	attempt := *sb
+	attempt.ctx = ctx
+
+	done := make(chan error, 1)
+	go func() {
+		done <- b.OnAttach(&attempt)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w of %s", errAttachTimeout, opts.Timeout)
+	}
+}
+
+// DependentBlock is a Block which needs other blocks to be attached
+// before it. DependsOn returns the symbols of those blocks, the blocks
+// are attached in the order of their dependencies:
+//
+// 		func (c *Cache) DependsOn() []string {
+// 			return []string{"db"}
+// 		}
+type DependentBlock interface {
+	Block
+	DependsOn() []string
+}
+
+// BootError is returned when the blocks cannot be booted. Missing lists the
+// dependencies of Block which are not attached and Cycle lists the blocks
+// depending on each other. Err is the error returned by OnAttach of Block
+type BootError struct {
+	Block   string
+	Missing []string
+	Cycle   []string
+	Err     error
+}
+
+// Error implements the error interface of Go
+func (be BootError) Error() string {
+	switch {
+	case len(be.Cycle) > 0:
+		return fmt.Sprintf("BootError: blocks have a dependency cycle: %s",
+			strings.Join(be.Cycle, " -> "))
+	case len(be.Missing) > 0:
+		return fmt.Sprintf("BootError: block =[ %s ]= depends on %s which are not attached",
+			be.Block, strings.Join(be.Missing, ", "))
+	default:
+		return fmt.Sprintf("BootError: block =[ %s ]= cannot be attached: %v", be.Block, be.Err)
+	}
+}
+
+// Unwrap returns the error returned by OnAttach of the block
+func (be BootError) Unwrap() error {
+	return be.Err
+}
+
+// blockDeps returns the lowercased dependency symbols of a block
+func blockDeps(b Block) []string {
+	db, ok := b.(DependentBlock)
+	if !ok {
+		return nil
+	}
+
+	var deps []string
+	for _, d := range db.DependsOn() {
+		deps = append(deps, strings.ToLower(d))
+	}
+	return deps
+}
+
+// sortBlocks returns the names of the blocks in the order they must be
+// attached. Blocks which do not depend on each other are ordered by their
+// names. Dependencies present inside booted are already attached
+func sortBlocks(blockList map[string]Block, booted map[string]Block) ([]string, error) {
+	names := make([]string, 0, len(blockList))
+	for name := range blockList {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pending := make(map[string]int)
+	dependents := make(map[string][]string)
+	for _, name := range names {
+		var missing []string
+		for _, dep := range blockDeps(blockList[name]) {
+			if _, ok := blockList[dep]; ok {
+				pending[name]++
+				dependents[dep] = append(dependents[dep], name)
+			} else if _, ok := booted[dep]; !ok {
+				missing = append(missing, dep)
+			}
+		}
+
+		if len(missing) > 0 {
+			return nil, BootError{Block: name, Missing: missing}
+		}
+	}
+
+	var ready, order []string
+	for _, name := range names {
+		if pending[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+
+		for _, d := range dependents[name] {
+			pending[d]--
+			if pending[d] == 0 {
+				ready = append(ready, d)
+				sort.Strings(ready)
+			}
+		}
+	}
+
+	if len(order) < len(names) {
+		return nil, BootError{Cycle: findCycle(blockList, pending)}
+	}
+	return order, nil
+}
+
+// findCycle returns a dependency cycle among the blocks which could not
+// be sorted, the first block is repeated at the end of the cycle
+func findCycle(blockList map[string]Block, pending map[string]int) []string {
+	var start string
+	for name, count := range pending {
+		if count > 0 && (start == "" || name < start) {
+			start = name
+		}
+	}
+
+	// every unsorted block depends on an unsorted block, so walking the
+	// dependencies from any of them ends up in a cycle
+	seen := make(map[string]int)
+	var path []string
+	for name := start; ; {
+		if i, ok := seen[name]; ok {
+			return append(path[i:], name)
+		}
+		seen[name] = len(path)
+		path = append(path, name)
+
+		for _, dep := range blockDeps(blockList[name]) {
+			if pending[dep] > 0 {
+				name = dep
+				break
+			}
+		}
+	}
+}
+
 // Plugin is executed plugins when RUBIK_ENV = ext.
 // Blocks which requires access to server but does need the
 // server to run. To run your extention block use
@@ -44,19 +262,41 @@ type Plugin interface {
 type App struct {
 	RouteTree
 	app        rubik
+	root       *rubik
 	blockName  string
 	CurrentURL string
 	Project    string
 	Args       string
+	// FlagSet holds the parsed flags of the plugin, Command is the
+	// RunID of the plugin followed by the selected command and
+	// Positional are the arguments left after the flags
+	FlagSet    *flag.FlagSet
+	Command    string
+	Positional []string
+	ctx        context.Context
+}
+
+// Context returns the context of OnAttach which is cancelled when the
+// Timeout of the BlockOptions of the block is reached
+//
+// 		func (db *DB) OnAttach(app *rubik.App) error {
+// 			return db.pool.PingContext(app.Context())
+// 		}
+func (sb *App) Context() context.Context {
+	if sb.ctx == nil {
+		return context.Background()
+	}
+	return sb.ctx
 }
 
 // Decode decodes the internal rubik server config into the struct
-// that you provide. It returns error if the config is not
+// that you provide. The name can be a dotted path to a nested table
+// like blocks.cache. It returns error if the config is not
 // un-marshalable OR if there is no config initialized by the given
 // name parameter
 func (sb *App) Decode(name string, target interface{}) error {
 	// check for target is pointer or not
-	val := sb.app.intermConfig.Get(name)
+	val := sb.notationMap().Get(name)
 	msg := fmt.Sprintf("AppDecodeError: block =[ %s ]= requires you to specify "+
 		"%s object inside your config/.toml file", sb.blockName, name)
 	if val == nil {
@@ -76,11 +316,35 @@ func (sb *App) Decode(name string, target interface{}) error {
 	return nil
 }
 
-// Config get config by name
-func (sb *App) Config(name string) interface{} {
-	if strings.Contains(name, ".") {
-		return nil
+// Logger returns the structured logger of rubik named after this block
+func (sb *App) Logger() *pkg.Logger {
+	logger := sb.app.logger
+	if logger == nil {
+		logger = pkg.DefaultLogger
 	}
 
-	return sb.app.intermConfig.Get(name)
+	if sb.blockName == "" {
+		return logger
+	}
+	return logger.Named(sb.blockName)
+}
+
+// Config get config by name, the name can be a dotted path like
+// database.host
+func (sb *App) Config(name string) interface{} {
+	return sb.notationMap().Get(name)
+}
+
+// ConfigView returns the typed accessors of the config of your server
+func (sb *App) ConfigView() ConfigView {
+	return ConfigView{source: sb.notationMap}
+}
+
+// notationMap returns the live config of rubik so that a block reads
+// the new config after it is reloaded
+func (sb *App) notationMap() ds.NotationMap {
+	if sb.root != nil {
+		return sb.root.notationMap()
+	}
+	return sb.app.intermConfig
 }
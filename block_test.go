@@ -1,7 +1,12 @@
 package rubik
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/rubikorg/blocks/ds"
 )
@@ -10,7 +15,29 @@ func TestAppConfig(t *testing.T) {
 	copy := &App{}
 	conf := copy.Config("ashish.test")
 	if conf != nil {
-		t.Error("App.Config did not return nil when you used a dot accessor")
+		t.Error("App.Config did not return nil for a missing dot accessor")
+	}
+}
+
+func TestAppConfigDotted(t *testing.T) {
+	nm := ds.NewNotationMap()
+	nm.Assign(map[string]interface{}{
+		"blocks": map[string]interface{}{
+			"cache": map[string]interface{}{"size": int64(64)},
+		},
+	})
+	nm.Flatten()
+
+	copy := &App{app: rubik{intermConfig: nm}}
+	if copy.Config("blocks.cache.size") != int64(64) {
+		t.Error("App.Config did not return the value of a dot accessor")
+	}
+
+	var cache struct {
+		Size int `json:"size"`
+	}
+	if err := copy.Decode("blocks.cache", &cache); err != nil || cache.Size != 64 {
+		t.Error("App.Decode did not decode nested table:", err, cache)
 	}
 }
 
@@ -28,3 +55,162 @@ func TestAppConfig2(t *testing.T) {
 		t.Error("App.Config did not return value 1 accessing a config")
 	}
 }
+
+type depBlock struct {
+	deps []string
+}
+
+func (db depBlock) OnAttach(app *App) error {
+	return nil
+}
+
+func (db depBlock) DependsOn() []string {
+	return db.deps
+}
+
+func TestSortBlocks(t *testing.T) {
+	blockList := map[string]Block{
+		"cache":   depBlock{deps: []string{"DB"}},
+		"db":      depBlock{},
+		"auth":    depBlock{deps: []string{"cache", "db"}},
+		"metrics": depBlock{},
+		"mailer":  depBlock{deps: []string{"config"}},
+	}
+	booted := map[string]Block{"config": depBlock{}}
+
+	order, err := sortBlocks(blockList, booted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(order, ",") != "db,cache,auth,mailer,metrics" {
+		t.Error("sortBlocks() returned wrong order:", order)
+	}
+}
+
+func TestSortBlocksErrors(t *testing.T) {
+	_, err := sortBlocks(map[string]Block{
+		"cache": depBlock{deps: []string{"db"}},
+	}, nil)
+	if be, ok := err.(BootError); !ok || be.Block != "cache" || be.Missing[0] != "db" {
+		t.Error("sortBlocks() did not report missing dependency:", err)
+	}
+
+	_, err = sortBlocks(map[string]Block{
+		"a":    depBlock{deps: []string{"b"}},
+		"b":    depBlock{deps: []string{"c"}},
+		"c":    depBlock{deps: []string{"a"}},
+		"free": depBlock{},
+	}, nil)
+	if be, ok := err.(BootError); !ok || strings.Join(be.Cycle, " ") != "a b c a" {
+		t.Error("sortBlocks() did not report the cycle:", err)
+	}
+}
+
+type flakyBlock struct {
+	fails int
+	calls int
+	wait  time.Duration
+}
+
+func (fb *flakyBlock) OnAttach(app *App) error {
+	fb.calls++
+	time.Sleep(fb.wait)
+	if fb.calls <= fb.fails {
+		return errors.New("not ready")
+	}
+	return nil
+}
+
+func TestBlockOptionsAttach(t *testing.T) {
+	fb := &flakyBlock{fails: 2}
+	opts := BlockOptions{Retries: 2, Backoff: time.Millisecond}
+	if err := opts.attach(fb, &App{}); err != nil || fb.calls != 3 {
+		t.Error("attach() did not retry the block:", err, fb.calls)
+	}
+
+	slow := &flakyBlock{wait: time.Millisecond * 50}
+	opts = BlockOptions{Timeout: time.Millisecond}
+	if err := opts.attach(slow, &App{}); err == nil {
+		t.Error("attach() did not time out")
+	}
+}
+
+// blockFunc attaches a block using the function
+type blockFunc func(*App) error
+
+// OnAttach implements the Block interface
+func (bf blockFunc) OnAttach(app *App) error { return bf(app) }
+
+// ctxBlock hangs until the context of the App is done on the first
+// call and attaches on the next one
+type ctxBlock struct {
+	calls    int32
+	canceled chan error
+}
+
+func (cb *ctxBlock) OnAttach(app *App) error {
+	if atomic.AddInt32(&cb.calls, 1) == 1 {
+		<-app.Context().Done()
+		cb.canceled <- app.Context().Err()
+		return errors.New("late result")
+	}
+	return nil
+}
+
+func TestBlockOptionsTimeout(t *testing.T) {
+	cb := &ctxBlock{canceled: make(chan error, 1)}
+	opts := BlockOptions{Timeout: time.Millisecond * 10, Retries: 2, Backoff: time.Millisecond}
+	if err := opts.attach(cb, &App{}); err != nil {
+		t.Fatal("attach() did not retry the block which timed out:", err)
+	}
+
+	if ctxErr := <-cb.canceled; ctxErr != context.DeadlineExceeded {
+		t.Error("the context of OnAttach was not cancelled:", ctxErr)
+	}
+
+	if calls := atomic.LoadInt32(&cb.calls); calls != 2 {
+		t.Error("attach() did not stop after the block attached, calls:", calls)
+	}
+
+	// a late success of every attempt is ignored
+	hang := blockFunc(func(app *App) error {
+		<-app.Context().Done()
+		return nil
+	})
+	opts = BlockOptions{Timeout: time.Millisecond * 5, Retries: 1, Backoff: time.Millisecond}
+	if err := opts.attach(hang, &App{}); !errors.Is(err, errAttachTimeout) {
+		t.Error("attach() did not fail after every attempt timed out:", err)
+	}
+}
+
+func TestBootOptionalBlocks(t *testing.T) {
+	defer func() {
+		app.failed = make(map[string]error)
+		app.blockOpts = make(map[string]BlockOptions)
+	}()
+
+	app.blockOpts["analytics"] = BlockOptions{Optional: true}
+	blockList := map[string]Block{
+		"analytics": &flakyBlock{fails: 1},
+		"db":        &flakyBlock{},
+	}
+
+	if err := bootBlocks(blockList, nil, true); err != nil {
+		t.Fatal("bootBlocks() failed for optional block:", err)
+	}
+
+	if app.failed["analytics"] == nil || blockList["analytics"] != nil {
+		t.Error("bootBlocks() did not record the failed optional block")
+	}
+
+	report := checkHealth(context.Background())
+	if report.Status != healthDegraded || report.Blocks["analytics"].Status != healthFailed {
+		t.Error("checkHealth() did not report the failed optional block:", report)
+	}
+
+	err := bootBlocks(map[string]Block{"db": &flakyBlock{fails: 1}}, nil, true)
+	if _, ok := err.(BootError); !ok {
+		t.Error("bootBlocks() did not fail for required block:", err)
+	}
+}
@@ -1,12 +1,12 @@
 package rubik
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
@@ -45,24 +45,32 @@ func (nfh notFoundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // server
 // The sequence of booting is as follows:
 //
-// 1. handle404Response()
-// 2. bootBlocks()
-// 3. bootStatic()
-// 4. bootRoutes()
+// 1. bootLogChannel()
+// 2. handle404Response()
+// 3. bootBlocks()
+// 4. bootStatic()
+// 5. bootBuiltins()
+// 6. bootRoutes()
 func boot(isREPLMode bool, isExtensionMode bool) error {
-	// go bootLogChannel()
 	// bootWsProcessControl()
 
 	if !isREPLMode {
+		err := bootLogChannel()
+		if err != nil {
+			app.logger.Error(err.Error())
+			return err
+		}
+
 		handle404Response()
-		err := bootBlocks(app.blocks, isExtensionMode)
+		err = bootBlocks(app.blocks, nil, isExtensionMode)
 		if err != nil {
-			pkg.ErrorMsg(err.Error())
+			app.logger.Error(err.Error())
 			return err
 		}
 	}
 
 	bootStatic(isExtensionMode)
+	bootBuiltins()
 
 	//c.checkForConfig()
 	var didError bool
@@ -83,20 +91,21 @@ func boot(isREPLMode bool, isExtensionMode bool) error {
 			if !strings.Contains(router.basePath, "rubik") {
 				// insert in tree
 				rinfo := RouteInfo{
-					BelongsTo:   strings.ReplaceAll(router.basePath, "/", ""),
-					Entity:      route.Entity,
-					Description: route.Description,
-					Path:        safeRoutePath(route.Path),
-					FullPath:    finalPath,
-					Method:      route.Method,
-					Responses:   route.ResponseDeclarations,
+					BelongsTo:     strings.ReplaceAll(router.basePath, "/", ""),
+					Entity:        route.Entity,
+					Description:   route.Description,
+					Path:          safeRoutePath(route.Path),
+					FullPath:      finalPath,
+					IsJSON:        route.JSON,
+					Export:        route.Export,
+					Method:        route.Method,
+					Responses:     route.ResponseDeclarations,
+					ResponseTypes: route.ResponseTypes,
 				}
 				app.routeTree.Routes = append(app.routeTree.Routes, rinfo)
 
 				if !isREPLMode && !isExtensionMode {
-					if os.Getenv("RUBIK_ENV") != "test" {
-						pkg.EmojiMsg("", finalPath)
-					}
+					app.logger.Emoji("", finalPath)
 				}
 			}
 
@@ -109,26 +118,57 @@ func boot(isREPLMode bool, isExtensionMode bool) error {
 
 			handler := func(writer http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 				defer req.Body.Close()
-				rubikWriter := RResponseWriter{
-					ResponseWriter: writer,
-				}
+				start := time.Now()
 				rubikReq := Request{
+					app:    app,
+					id:     requestID(req),
 					Raw:    req,
-					Writer: rubikWriter,
-					Ctx:    context.Background(),
+					Params: ps,
+					Writer: RResponseWriter{ResponseWriter: writer},
+					Ctx:    req.Context(),
+				}
+				writer.Header().Set(headerRequestID, rubikReq.id)
+				rubikReq.Writer.beforeHeader = func() {
+					dispatchPhase(Phase.BeforeResponse, &rubikReq)
 				}
 				hookCtx := HookContext{
-					Request: req,
-					Ctx:     make(map[string]interface{}),
+					Request:   req,
+					Ctx:       make(map[string]interface{}),
+					Route:     finalPath,
+					RequestID: rubikReq.id,
+				}
+
+				// finish runs the hooks which are meant to be executed after the
+				// response is written, no matter at which stage it was written
+				finish := func() {
+					dispatchPhase(Phase.AfterResponse, &rubikReq)
+					hookCtx.Status = rubikReq.Writer.status
+					if hookCtx.Status == 0 {
+						// nothing was written and net/http sends 200 by default
+						hookCtx.Status = http.StatusOK
+					}
+					hookCtx.Response = rubikReq.Writer.data
+					hookCtx.Size = rubikReq.Writer.size
+					// the hooks run after the handler returns so they get a
+					// copy of the headers which net/http can still modify
+					hookCtx.Header = rubikReq.Writer.Header().Clone()
+					hookCtx.Entity = rubikReq.Entity
+					hookCtx.Error = rubikReq.err
+					hookCtx.Latency = time.Since(start)
+					writeAccessLog(&hookCtx, rubikReq.id, start)
+					enqueueAfterHooks(&hookCtx)
+				}
+
+				if dispatchPhase(Phase.BeforeGuard, &rubikReq) {
+					finish()
+					return
 				}
 
 				if len(route.Guards) > 0 {
 					for _, g := range route.Guards {
 						g(&rubikReq)
 						if rubikReq.Writer.written {
-							hookCtx.Status = rubikReq.Writer.status
-							hookCtx.Response = rubikReq.Writer.data
-							go dispatchHooks(afterHooks, &hookCtx)
+							finish()
 							return
 						}
 					}
@@ -140,32 +180,34 @@ func boot(isREPLMode bool, isExtensionMode bool) error {
 					var err error
 					en, err = inject(req, ps, en, route.Validation)
 					if err != nil {
-						writeResponse(&rubikWriter, 400, Content.Text, []byte(err.Error()))
+						rubikReq.err = err
+						writeResponse(&rubikReq.Writer, 400, Content.Text, []byte(err.Error()))
+						finish()
 						return
 					}
 
 					rubikReq.Entity = en
 				}
 
+				if dispatchPhase(Phase.AfterInjection, &rubikReq) {
+					finish()
+					return
+				}
+
 				dispatchHooks(beforeHooks, &hookCtx)
 
 				if len(route.Middlewares) > 0 {
 					for _, m := range route.Middlewares {
 						m(&rubikReq)
 						if rubikReq.Writer.written {
-							hookCtx.Status = rubikReq.Writer.status
-							hookCtx.Response = rubikReq.Writer.data
-							go dispatchHooks(afterHooks, &hookCtx)
+							finish()
 							return
 						}
 					}
 				}
 
 				route.Controller(&rubikReq)
-
-				hookCtx.Status = rubikReq.Writer.status
-				hookCtx.Response = rubikReq.Writer.data
-				go dispatchHooks(afterHooks, &hookCtx)
+				finish()
 			}
 
 			if route.Controller != nil {
@@ -180,7 +222,8 @@ func boot(isREPLMode bool, isExtensionMode bool) error {
 					}
 				}
 			} else {
-				pkg.WarnMsg("ROUTE_NOT_BOOTED: No controller assigned for route: " + finalPath)
+				app.logger.Warn("ROUTE_NOT_BOOTED: No controller assigned for route",
+					"route", finalPath)
 			}
 		}
 	}
@@ -194,7 +237,12 @@ func boot(isREPLMode bool, isExtensionMode bool) error {
 	}
 
 	if !isREPLMode {
-		err := bootBlocks(app.afterBlocks, isExtensionMode)
+		err := bootBlocks(app.afterBlocks, app.blocks, isExtensionMode)
+		if err != nil {
+			return err
+		}
+
+		err = bootInjections()
 		if err != nil {
 			return err
 		}
@@ -219,41 +267,73 @@ func writeResponse(w http.ResponseWriter, status int, contype string, body []byt
 // bootBlocks initializes all the attached blocks and calls
 // the onAttach method to boot it's requirements.
 // A block is said to be attached only if the return error
-// value is nil
-func bootBlocks(blockList map[string]Block, isExtensionMode bool) error {
+// value is nil. The blocks are attached in the order of their
+// dependencies, the dependencies inside booted are already attached
+func bootBlocks(blockList map[string]Block, booted map[string]Block,
+	isExtensionMode bool) error {
+	order, err := sortBlocks(blockList, booted)
+	if err != nil {
+		return err
+	}
+
 	if len(blockList) > 0 {
-		for k, v := range blockList {
+		for _, k := range order {
+			v := blockList[k]
 			sb := &App{
 				app:        *app,
+				root:       app,
 				blockName:  k,
 				CurrentURL: app.url,
 				RouteTree:  app.routeTree,
 				Args:       os.Getenv("RUBIK_ARGS"),
 			}
 
-			err := v.OnAttach(sb)
+			opts := app.blockOpts[k]
+			err := failedDependency(v)
+			if err == nil {
+				err = opts.attach(v, sb)
+			}
+
 			if err != nil {
-				return err
+				if !opts.Optional {
+					return BootError{Block: k, Err: err}
+				}
+
+				app.logger.Warn("Optional block failed to attach", "block", k, "error", err)
+				app.failed[k] = err
+				delete(blockList, k)
+				continue
 			}
 
 			if !isExtensionMode {
-				msg := fmt.Sprintf("📦 Attached =[ @(%s) ]=", k)
-				msg = tint.Init().Exp(msg, tint.Cyan.Bold())
-				fmt.Println(msg)
+				app.logger.Info("📦 Block attached", "block", k)
 			}
 		}
 	}
 	return nil
 }
 
+// failedDependency returns an error if any dependency of the block is an
+// optional block which failed to attach
+func failedDependency(b Block) error {
+	for _, dep := range blockDeps(b) {
+		if err := app.failed[dep]; err != nil {
+			return fmt.Errorf("dependency %s failed to attach: %v", dep, err)
+		}
+	}
+	return nil
+}
+
 func bootPlugin() error {
-	if len(app.extensions) == 0 {
-		return errors.New("No Rubik extensions plugged in")
+	if len(duplicatePlugins) > 0 {
+		return fmt.Errorf("PluginError: more than one plugin uses the RunID %s",
+			strings.Join(duplicatePlugins, ", "))
 	}
 
 	// TODO: RUBIK_PROJ, RUBIK_ARGS should be inside a constants file for avoiding typo errors
 	sb := &App{
 		app:        *app,
+		root:       app,
 		CurrentURL: app.url,
 		RouteTree:  app.routeTree,
 		Project:    os.Getenv("RUBIK_PROJ"),
@@ -261,8 +341,13 @@ func bootPlugin() error {
 	}
 
 	envPlugin := os.Getenv("RUBIK_PLUGIN")
+	if envPlugin == "" {
+		listPlugins(os.Stdout)
+		return nil
+	}
+
 	var plugin Plugin
-	for _, exb := range app.extensions {
+	for _, exb := range availablePlugins() {
 		if exb.RunID() == envPlugin {
 			plugin = exb
 		}
@@ -272,17 +357,27 @@ func bootPlugin() error {
 		return fmt.Errorf("%s plugin not plugged, Import this plugin in main.go file", envPlugin)
 	}
 
-	msg := fmt.Sprintf("\n 🧩 Plugging extension @(%s)", plugin.Name())
-	msg = tint.Init().Exp(msg, tint.Green.Bold())
-	fmt.Println(msg)
+	args, err := splitArgs(sb.Args)
+	if err != nil {
+		return argsError{err}
+	}
 
-	sb.blockName = plugin.Name()
-	err := plugin.OnPlug(sb)
+	cmd, err := parsePluginArgs(plugin, sb, args, os.Stderr)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// the banner goes to stderr so that it is not part of the output of
+	// the plugin which can be JSON or TOML
+	msg := fmt.Sprintf("\n 🧩 Plugging extension @(%s)", plugin.Name())
+	msg = tint.Init().Exp(msg, tint.Green.Bold())
+	fmt.Fprintln(os.Stderr, msg)
+
+	sb.blockName = plugin.Name()
+	if cmd != nil {
+		return cmd.Run(sb)
+	}
+	return plugin.OnPlug(sb)
 }
 
 // bootStatic boots the ServeFiles handler httprouter
@@ -292,8 +387,8 @@ func bootPlugin() error {
 func bootStatic(isExtensionMode bool) {
 	if _, err := os.Stat(pkg.GetStaticFolderPath()); err == nil {
 		app.mux.ServeFiles("/static/*filepath", http.Dir("./static"))
-		if os.Getenv("RUBIK_ENV") != "test" && !isExtensionMode {
-			pkg.EmojiMsg("⚡️", "/static")
+		if !isExtensionMode {
+			app.logger.Emoji("⚡️", "/static")
 		}
 	}
 }
@@ -315,6 +410,7 @@ func handleErrorResponse(err error, writer http.ResponseWriter, rc *HookContext)
 	}
 
 	// TODO: fix this mess and in the end afterHooks
+	rc.Error = err
 	writer.WriteHeader(500)
 	if err.Error() != "" && isDevEnv {
 		serr, ok := err.(tracer)
@@ -338,7 +434,7 @@ func handleErrorResponse(err error, writer http.ResponseWriter, rc *HookContext)
 		writer.Header().Set("Content-Type", "application/json")
 		rc.Response = b
 		rc.Status = 500
-		go dispatchHooks(afterHooks, rc)
+		enqueueAfterHooks(rc)
 		writer.Write(b)
 		return
 	}
@@ -347,7 +443,7 @@ func handleErrorResponse(err error, writer http.ResponseWriter, rc *HookContext)
 
 // dispatchHooks just calls all the hooks passed as the argument
 // this is generally used to call before/after request hooks
-// and is intended to be executed as a goroutine
+// and is executed by the after hook workers for afterHooks
 func dispatchHooks(hooks []RequestHook, rc *HookContext) {
 	if len(hooks) > 0 {
 		for _, h := range hooks {
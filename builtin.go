@@ -0,0 +1,224 @@
+package rubik
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+)
+
+// RoutesPlugin prints the routes of your server as a table or as JSON
+//
+// 		RUBIK_ENV=plugin RUBIK_PLUGIN=routes RUBIK_ARGS="-format json" go run .
+type RoutesPlugin struct {
+	format string
+	out    io.Writer
+}
+
+// OnPlug implements the Plugin interface
+func (rp *RoutesPlugin) OnPlug(app *App) error {
+	desc := describeAPI(app.RouteTree)
+	switch rp.format {
+	case "json":
+		return writeJSON(pluginOutput(rp.out), desc)
+	case "table":
+		tw := tabwriter.NewWriter(pluginOutput(rp.out), 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "METHOD\tPATH\tROUTER\tDESCRIPTION")
+		for _, r := range desc.Routes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Method, r.Path, r.Router, r.Description)
+		}
+		return tw.Flush()
+	}
+	return argsError{fmt.Errorf("unknown format %s, use table or json", rp.format)}
+}
+
+// Flags implements the FlagPlugin interface
+func (rp *RoutesPlugin) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&rp.format, "format", "table", "output format: table or json")
+}
+
+// Name implements the Plugin interface
+func (rp *RoutesPlugin) Name() string { return "Rubik Routes" }
+
+// RunID implements the Plugin interface
+func (rp *RoutesPlugin) RunID() string { return "routes" }
+
+// Description implements the DescribedPlugin interface
+func (rp *RoutesPlugin) Description() string { return "prints the routes of the server" }
+
+// ConfigPlugin prints the merged config of your server with the secrets
+// redacted as TOML or JSON
+type ConfigPlugin struct {
+	format string
+	out    io.Writer
+}
+
+// OnPlug implements the Plugin interface
+func (cp *ConfigPlugin) OnPlug(app *App) error {
+	config := RedactedConfig()
+	switch cp.format {
+	case "json":
+		return writeJSON(pluginOutput(cp.out), config)
+	case "toml":
+		return toml.NewEncoder(pluginOutput(cp.out)).Encode(config)
+	}
+	return argsError{fmt.Errorf("unknown format %s, use toml or json", cp.format)}
+}
+
+// Flags implements the FlagPlugin interface
+func (cp *ConfigPlugin) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&cp.format, "format", "toml", "output format: toml or json")
+}
+
+// Name implements the Plugin interface
+func (cp *ConfigPlugin) Name() string { return "Rubik Config" }
+
+// RunID implements the Plugin interface
+func (cp *ConfigPlugin) RunID() string { return "config" }
+
+// Description implements the DescribedPlugin interface
+func (cp *ConfigPlugin) Description() string {
+	return "prints the merged config with secrets redacted"
+}
+
+// ExportPlugin writes the APIDescription, the OpenAPI document, a Postman
+// collection or a .http file of your server to a file or to the standard
+// output, commit it to see the route changes in your diffs. The requests
+// of the collections are sent to the host and port of the config unless
+// -base is given
+//
+// 		RUBIK_ENV=plugin RUBIK_PLUGIN=export RUBIK_ARGS="-format openapi -out api.json" go run .
+// 		RUBIK_ENV=plugin RUBIK_PLUGIN=export RUBIK_ARGS="-format http -out api.http" go run .
+type ExportPlugin struct {
+	file     string
+	format   string
+	base     string
+	exported bool
+	out      io.Writer
+}
+
+// OnPlug implements the Plugin interface
+func (ep *ExportPlugin) OnPlug(app *App) error {
+	var buf bytes.Buffer
+	var export interface{}
+	switch ep.format {
+	case "", "rubik":
+		export = describeAPI(app.RouteTree)
+	case "openapi":
+		opts := OpenAPIOptions{ExportedOnly: ep.exported}
+		if openAPIOpts != nil {
+			opts = *openAPIOpts
+			opts.ExportedOnly = opts.ExportedOnly || ep.exported
+		}
+		export = OpenAPI(app.RouteTree, opts)
+	case "postman":
+		export = PostmanCollection(ep.description(app), ep.baseURL(app))
+	case "http":
+		buf.Write(HTTPFile(ep.description(app), ep.baseURL(app)))
+	default:
+		return argsError{fmt.Errorf("unknown format %s, use rubik, openapi, postman "+
+			"or http", ep.format)}
+	}
+
+	if export != nil {
+		if err := writeJSON(&buf, export); err != nil {
+			return err
+		}
+	}
+
+	if ep.file == "" || ep.file == "-" {
+		_, err := pluginOutput(ep.out).Write(buf.Bytes())
+		return err
+	}
+	return ioutil.WriteFile(ep.file, buf.Bytes(), 0644)
+}
+
+// baseURL returns the URL the requests of the collections are sent to
+func (ep *ExportPlugin) baseURL(app *App) string {
+	if ep.base != "" {
+		return ep.base
+	}
+	return configBaseURL(app.notationMap())
+}
+
+// description returns the routes exported in the collections
+func (ep *ExportPlugin) description(app *App) APIDescription {
+	desc := describeAPI(app.RouteTree)
+	if ep.exported {
+		var routes []APIRoute
+		for _, r := range desc.Routes {
+			if r.Export {
+				routes = append(routes, r)
+			}
+		}
+		desc.Routes = routes
+	}
+	return desc
+}
+
+// Flags implements the FlagPlugin interface
+func (ep *ExportPlugin) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&ep.file, "out", "-", "file to write the export to, - for stdout")
+	fs.StringVar(&ep.format, "format", "rubik",
+		"export format: rubik, openapi, postman or http")
+	fs.StringVar(&ep.base, "base", "", "base URL of the requests of postman and http, "+
+		"defaults to the host and port of the config")
+	fs.BoolVar(&ep.exported, "exported", false, "export only the routes with Export set")
+}
+
+// Name implements the Plugin interface
+func (ep *ExportPlugin) Name() string { return "Rubik Export" }
+
+// RunID implements the Plugin interface
+func (ep *ExportPlugin) RunID() string { return "export" }
+
+// Description implements the DescribedPlugin interface
+func (ep *ExportPlugin) Description() string {
+	return "exports the API as JSON, OpenAPI, a Postman collection or a .http file"
+}
+
+// builtinPlugins returns the plugins shipped with rubik which are
+// available in plugin mode unless a plugin with the same RunID is plugged
+func builtinPlugins() []Plugin {
+	return []Plugin{&RoutesPlugin{}, &ConfigPlugin{}, &ExportPlugin{}, &ClientPlugin{},
+		&TypeScriptPlugin{}}
+}
+
+// availablePlugins returns the plugged plugins and the built-in plugins
+// not replaced by them
+func availablePlugins() []Plugin {
+	plugins := append([]Plugin{}, app.extensions...)
+	for _, b := range builtinPlugins() {
+		replaced := false
+		for _, p := range app.extensions {
+			if p.RunID() == b.RunID() {
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			plugins = append(plugins, b)
+		}
+	}
+	return plugins
+}
+
+func pluginOutput(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,118 @@
+package rubik
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type describeEn struct {
+	Entity
+	ID    int    `rubik:"id|param!"`
+	Name  string `rubik:"body"`
+	Limit int
+}
+
+func testRouteTree() RouteTree {
+	return RouteTree{Routes: []RouteInfo{
+		{FullPath: "/users/:id", Method: "POST", BelongsTo: "users",
+			Description: "Updates a user", Entity: describeEn{}},
+		{FullPath: "/users/:id", BelongsTo: "users", Description: "Gets a user"},
+		{FullPath: "/", BelongsTo: "", Description: "Index"},
+	}}
+}
+
+func TestDescribeAPI(t *testing.T) {
+	desc := describeAPI(testRouteTree())
+	if len(desc.Routes) != 3 || desc.Routes[0].Path != "/" ||
+		desc.Routes[1].Method != "GET" || desc.Routes[2].Method != "POST" {
+		t.Fatal("describeAPI() did not sort the routes:", desc.Routes)
+	}
+
+	fields := desc.Routes[2].Fields
+	if len(fields) != 3 || fields[0].Key != "id" || fields[0].In != "param" ||
+		!fields[0].Required || fields[0].Type != "integer" || fields[1].In != "body" ||
+		fields[2].Key != "limit" || fields[2].In != "query" {
+		t.Error("describeAPI() returned wrong fields:", fields)
+	}
+}
+
+func TestDescribeMultiMethodRoute(t *testing.T) {
+	desc := describeAPI(RouteTree{Routes: []RouteInfo{
+		{FullPath: "/items", Method: "get|POST", Entity: describeEn{}},
+	}})
+	if len(desc.Routes) != 2 || desc.Routes[0].Method != "GET" ||
+		desc.Routes[1].Method != "POST" || desc.Routes[1].Entity != "describeEn" {
+		t.Error("describeAPI() did not describe the route once per method:", desc.Routes)
+	}
+}
+
+func TestRoutesPlugin(t *testing.T) {
+	var buf bytes.Buffer
+	rp := &RoutesPlugin{format: "table", out: &buf}
+	if err := rp.OnPlug(&App{RouteTree: testRouteTree()}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[3], "POST") {
+		t.Error("RoutesPlugin did not print the table:", buf.String())
+	}
+
+	buf.Reset()
+	rp.format = "json"
+	if err := rp.OnPlug(&App{RouteTree: testRouteTree()}); err != nil {
+		t.Fatal(err)
+	}
+
+	var desc APIDescription
+	if err := json.Unmarshal(buf.Bytes(), &desc); err != nil || len(desc.Routes) != 3 {
+		t.Error("RoutesPlugin did not print JSON:", err, buf.String())
+	}
+
+	rp.format = "xml"
+	if err := rp.OnPlug(&App{}); exitCode(err) != 2 {
+		t.Error("RoutesPlugin did not reject unknown format:", err)
+	}
+}
+
+func TestConfigPlugin(t *testing.T) {
+	oldMap, oldSecrets := app.configMap, app.secretKeys
+	defer func() { app.configMap, app.secretKeys = oldMap, oldSecrets }()
+
+	app.configMap = map[string]interface{}{"port": "8000", "password": "s3cr3t"}
+	app.secretKeys = []string{"password"}
+
+	var buf bytes.Buffer
+	cp := &ConfigPlugin{format: "toml", out: &buf}
+	if err := cp.OnPlug(&App{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "s3cr3t") || !strings.Contains(buf.String(), "8000") {
+		t.Error("ConfigPlugin did not redact the config:", buf.String())
+	}
+}
+
+func TestExportPlugin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rubik-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "api.json")
+	ep := &ExportPlugin{file: file}
+	if err := ep.OnPlug(&App{RouteTree: testRouteTree()}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil || !strings.Contains(string(b), "\"/users/:id\"") {
+		t.Error("ExportPlugin did not write the export:", err)
+	}
+}
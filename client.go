@@ -57,6 +57,7 @@ type Payload struct {
 	path         string
 	requestType  string
 	json         bool
+	gob          bool
 	urlencoded   bool
 	formData     bool
 	headers      url.Values
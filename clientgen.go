@@ -0,0 +1,439 @@
+package rubik
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// rubikImportPath is the import path of this package used by the
+// generated clients
+const rubikImportPath = "github.com/rubikorg/rubik"
+
+// ClientOptions configures the Go client generated by GenerateClient.
+// Package is the name of the generated package and All includes the
+// routes which are not marked with Export
+type ClientOptions struct {
+	Package string
+	All     bool
+}
+
+// clientMethod is a method of the generated client
+type clientMethod struct {
+	Name        string
+	Method      string
+	Path        string
+	Description string
+	Responses   []string
+	Entity      string
+	NewEntity   bool
+	Args        []string
+	PointTo     string
+	Params      []string
+	JSON        bool
+	FormData    bool
+	Result      string
+	Call        string
+}
+
+// clientFile is the data of the client template
+type clientFile struct {
+	Package string
+	Service string
+	Rubik   string
+	UsesFmt bool
+	Imports []clientImport
+	Methods []clientMethod
+	Skipped []string
+}
+
+type clientImport struct {
+	Alias string
+	Path  string
+}
+
+// importSet assigns the aliases of the packages used by the client
+type importSet struct {
+	aliases map[string]string
+	used    map[string]bool
+}
+
+func (is *importSet) alias(pkgPath string) string {
+	if a, ok := is.aliases[pkgPath]; ok {
+		return a
+	}
+
+	base := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, path.Base(pkgPath))
+	if base == "" || unicode.IsDigit(rune(base[0])) {
+		base = "pkg" + base
+	}
+
+	a := base
+	for i := 2; is.used[a]; i++ {
+		a = base + strconv.Itoa(i)
+	}
+	is.aliases[pkgPath] = a
+	is.used[a] = true
+	return a
+}
+
+func (is *importSet) list() []clientImport {
+	var imports []clientImport
+	for p, a := range is.aliases {
+		if p == rubikImportPath || p == "time" {
+			continue
+		}
+		imports = append(imports, clientImport{Alias: a, Path: p})
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Path < imports[j].Path })
+	return imports
+}
+
+// typeExpr returns the Go expression of the type using the aliases of
+// the imports
+func (is *importSet) typeExpr(t reflect.Type) (string, error) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		e, err := is.typeExpr(t.Elem())
+		return "*" + e, err
+	case reflect.Slice:
+		e, err := is.typeExpr(t.Elem())
+		return "[]" + e, err
+	case reflect.Map:
+		k, err := is.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		v, err := is.typeExpr(t.Elem())
+		return "map[" + k + "]" + v, err
+	}
+
+	if t.Name() == "" {
+		return t.String(), nil
+	}
+	if t.PkgPath() == "" {
+		return t.Name(), nil
+	}
+
+	if t.PkgPath() == "main" || strings.HasSuffix(t.PkgPath(), "/main") {
+		return "", fmt.Errorf("ClientGenError: %s is declared in package main and cannot "+
+			"be imported by the client, move it to another package", t.Name())
+	}
+	if !unicode.IsUpper(rune(t.Name()[0])) {
+		return "", fmt.Errorf("ClientGenError: %s.%s is not exported and cannot be used "+
+			"by the client", t.PkgPath(), t.Name())
+	}
+	return is.alias(t.PkgPath()) + "." + t.Name(), nil
+}
+
+// goIdent converts a path parameter into a Go identifier
+func goIdent(name string) string {
+	var sb strings.Builder
+	upper := false
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = sb.Len() > 0
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+
+	id := sb.String()
+	if id == "" || unicode.IsDigit(rune(id[0])) {
+		id = "p" + id
+	}
+	return id
+}
+
+// successType returns the response type of the lowest 2xx status
+func successType(r APIRoute) (reflect.Type, bool) {
+	var statuses []int
+	for status := range r.ResponseTypes {
+		if status >= 200 && status < 300 {
+			statuses = append(statuses, status)
+		}
+	}
+	if len(statuses) == 0 {
+		return nil, false
+	}
+	sort.Ints(statuses)
+	return r.ResponseTypes[statuses[0]], true
+}
+
+// embedsEntity reports whether the struct embeds rubik.Entity which the
+// generated methods use to describe the request
+func embedsEntity(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type == reflect.TypeOf(Entity{}) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientCalls are the methods of rubik.Client for the HTTP methods
+var clientCalls = map[string]string{GET: "Get", POST: "Post", PUT: "Put", DELETE: "Delete"}
+
+func buildClientMethod(r APIRoute, is *importSet) (clientMethod, error) {
+	m := clientMethod{
+		Name:        capitalize(operationID(r.Method, r.Path)),
+		Method:      r.Method,
+		Path:        r.Path,
+		Description: r.Description,
+		Call:        clientCalls[r.Method],
+		Entity:      "rubik.BlankRequestEntity",
+		NewEntity:   true,
+	}
+
+	var statuses []int
+	for status := range r.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		m.Responses = append(m.Responses, fmt.Sprintf("%d: %s", status, r.Responses[status]))
+	}
+
+	if r.EntityType != nil {
+		if !embedsEntity(r.EntityType) {
+			return m, fmt.Errorf("ClientGenError: the entity %s of %s %s does not embed "+
+				"rubik.Entity and cannot be sent by the client", r.EntityType, r.Method, r.Path)
+		}
+
+		e, err := is.typeExpr(r.EntityType)
+		if err != nil {
+			return m, err
+		}
+		m.Entity = e
+		m.NewEntity = false
+		m.Args = append(m.Args, "en "+e)
+	}
+
+	// path parameters are read from the param fields of the entity or
+	// passed as arguments of the method
+	_, names := openAPIPath(r.Path)
+	for _, name := range names {
+		param := ""
+		for _, f := range r.Fields {
+			if f.In == "param" && strings.EqualFold(f.Key, name) {
+				param = "fmt.Sprint(en." + f.Name + ")"
+				break
+			}
+		}
+
+		if param == "" {
+			param = goIdent(name)
+			m.Args = append(m.Args, param+" string")
+		}
+		m.Params = append(m.Params, param)
+	}
+	m.PointTo = paramSyntax.ReplaceAllString(r.Path, "$$")
+
+	for _, f := range r.Fields {
+		m.JSON = m.JSON || f.In == "body"
+		m.FormData = m.FormData || f.In == "form"
+	}
+
+	if t, ok := successType(r); ok {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		res, err := is.typeExpr(t)
+		if err != nil {
+			return m, err
+		}
+		m.Result = res
+	}
+	return m, nil
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by rubik. DO NOT EDIT.
+
+// Package {{.Package}} is the client of the {{.Service}} service
+package {{.Package}}
+
+import (
+{{- if .UsesFmt}}
+	"fmt"
+{{- end}}
+	"time"
+
+	"{{.Rubik}}"
+{{- range .Imports}}
+	{{.Alias}} "{{.Path}}"
+{{- end}}
+)
+
+// Client calls the routes of the {{.Service}} service
+type Client struct {
+	*rubik.Client
+}
+
+// New returns the Client of the service running at baseURL
+func New(baseURL string, timeout time.Duration) *Client {
+	return &Client{rubik.NewClient(baseURL, timeout)}
+}
+{{range .Methods}}
+// {{.Name}} calls {{.Method}} {{.Path}}{{if .Description}}
+//
+// {{.Description}}{{end}}{{if .Responses}}
+//
+// Responses:{{range .Responses}}
+// 	{{.}}{{end}}{{end}}
+func (c *Client) {{.Name}}({{range $i, $a := .Args}}{{if $i}}, {{end}}{{$a}}{{end}}) ({{if .Result}}*{{.Result}}, {{end}}rubik.Response, error) {
+	{{- if .NewEntity}}
+	var en {{.Entity}}
+	{{- end}}
+	en.PointTo = "{{.PointTo}}"
+	{{- if .Params}}
+	en.Params = []string{ {{- range $i, $p := .Params}}{{if $i}}, {{end}}{{$p}}{{end -}} }
+	{{- end}}
+	{{- if .JSON}}
+	en.JSON = true
+	{{- end}}
+	{{- if .FormData}}
+	en.FormData = true
+	{{- end}}
+	{{- if .Result}}
+	out := new({{.Result}})
+	en.Infer = out
+	resp, err := c.{{.Call}}(en)
+	return out, resp, err
+	{{- else}}
+	return c.{{.Call}}(en)
+	{{- end}}
+}
+{{end}}
+{{- range .Skipped}}
+// {{.}}
+{{- end}}
+`))
+
+// GenerateClient returns the source of a Go package with one method per
+// route of the description. The methods reuse the entity types of your
+// server so the requests are checked by the compiler, the entities must
+// embed rubik.Entity and be exported from a package other than main
+func GenerateClient(desc APIDescription, opts ClientOptions) ([]byte, error) {
+	if opts.Package == "" {
+		opts.Package = "client"
+	}
+
+	service := desc.Service
+	if service == "" {
+		service = opts.Package
+	}
+
+	is := &importSet{
+		aliases: map[string]string{rubikImportPath: "rubik", "time": "time"},
+		used:    map[string]bool{"fmt": true, "time": true, "rubik": true},
+	}
+	file := clientFile{Package: opts.Package, Service: service, Rubik: rubikImportPath}
+	names := make(map[string]bool)
+	for _, r := range desc.Routes {
+		if !opts.All && !r.Export {
+			continue
+		}
+
+		if clientCalls[r.Method] == "" {
+			file.Skipped = append(file.Skipped, fmt.Sprintf("%s %s is skipped as rubik.Client "+
+				"does not support %s", r.Method, r.Path, r.Method))
+			continue
+		}
+
+		m, err := buildClientMethod(r, is)
+		if err != nil {
+			return nil, err
+		}
+
+		for base, i := m.Name, 2; names[m.Name]; i++ {
+			m.Name = base + strconv.Itoa(i)
+		}
+		names[m.Name] = true
+		for _, p := range m.Params {
+			file.UsesFmt = file.UsesFmt || strings.HasPrefix(p, "fmt.")
+		}
+		file.Methods = append(file.Methods, m)
+	}
+	file.Imports = is.list()
+
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, file); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("ClientGenError: generated code is not valid: %v", err)
+	}
+	return src, nil
+}
+
+// ClientPlugin generates the Go client of your exported routes
+//
+// 		RUBIK_ENV=plugin RUBIK_PLUGIN=client RUBIK_ARGS="-out client/users.go -package client" go run .
+type ClientPlugin struct {
+	file string
+	pkg  string
+	all  bool
+	out  io.Writer
+}
+
+// OnPlug implements the Plugin interface
+func (cp *ClientPlugin) OnPlug(app *App) error {
+	src, err := GenerateClient(describeAPI(app.RouteTree),
+		ClientOptions{Package: cp.pkg, All: cp.all})
+	if err != nil {
+		return err
+	}
+
+	if cp.file == "" || cp.file == "-" {
+		_, err := pluginOutput(cp.out).Write(src)
+		return err
+	}
+	return ioutil.WriteFile(cp.file, src, 0644)
+}
+
+// Flags implements the FlagPlugin interface
+func (cp *ClientPlugin) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&cp.file, "out", "-", "file to write the client to, - for stdout")
+	fs.StringVar(&cp.pkg, "package", "client", "package name of the client")
+	fs.BoolVar(&cp.all, "all", false, "generate methods for the routes without Export")
+}
+
+// Name implements the Plugin interface
+func (cp *ClientPlugin) Name() string { return "Rubik Client Generator" }
+
+// RunID implements the Plugin interface
+func (cp *ClientPlugin) RunID() string { return "client" }
+
+// Description implements the DescribedPlugin interface
+func (cp *ClientPlugin) Description() string {
+	return "generates the Go client of the exported routes"
+}
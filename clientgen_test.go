@@ -0,0 +1,103 @@
+package rubik
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rubikorg/rubik/pkg"
+)
+
+func TestGenerateClient(t *testing.T) {
+	desc := APIDescription{Service: "users", Routes: []APIRoute{
+		{Method: "GET", Path: "/users/:id/files/*name", Export: true,
+			Description: "Gets a file of the user", Responses: map[int]string{404: "Not found"},
+			ResponseTypes: map[int]reflect.Type{200: reflect.TypeOf(pkg.WorkspaceConfig{})}},
+		{Method: "POST", Path: "/download", Export: true,
+			EntityType: reflect.TypeOf(DownloadRequestEntity{}),
+			Fields:     entityFields(DownloadRequestEntity{})},
+		{Method: "PATCH", Path: "/users", Export: true},
+		{Method: "GET", Path: "/internal"},
+	}}
+
+	src, err := GenerateClient(desc, ClientOptions{Package: "users"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := string(src)
+	for _, want := range []string{
+		"package users",
+		`pkg "github.com/rubikorg/rubik/pkg"`,
+		"func (c *Client) GetUsersIdFilesName(id string, name string) (*pkg.WorkspaceConfig, rubik.Response, error)",
+		`en.PointTo = "/users/$/files/$"`,
+		"en.Params = []string{id, name}",
+		"func (c *Client) PostDownload(en rubik.DownloadRequestEntity) (rubik.Response, error)",
+		"404: Not found",
+		"PATCH /users is skipped",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("GenerateClient() output does not contain %s\n%s", want, code)
+		}
+	}
+
+	if strings.Contains(code, "Internal") {
+		t.Error("GenerateClient() generated a route which is not exported")
+	}
+}
+
+func TestGenerateClientErrors(t *testing.T) {
+	desc := describeAPI(testRouteTree())
+	_, err := GenerateClient(desc, ClientOptions{All: true})
+	if err == nil || !strings.Contains(err.Error(), "not exported") {
+		t.Error("GenerateClient() did not reject unexported entity:", err)
+	}
+
+	desc = APIDescription{Routes: []APIRoute{{Method: "POST", Path: "/config", Export: true,
+		EntityType: reflect.TypeOf(pkg.WorkspaceConfig{})}}}
+	_, err = GenerateClient(desc, ClientOptions{})
+	if err == nil || !strings.Contains(err.Error(), "does not embed rubik.Entity") {
+		t.Error("GenerateClient() did not reject entity without rubik.Entity:", err)
+	}
+}
+
+func TestGenerateClientBuilds(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil || testing.Short() {
+		t.Skip("the go tool is needed to build the generated client")
+	}
+
+	desc := APIDescription{Service: "users", Routes: []APIRoute{
+		{Method: "GET", Path: "/users/:id", Export: true,
+			ResponseTypes: map[int]reflect.Type{200: reflect.TypeOf(&pkg.WorkspaceConfig{})}},
+		{Method: "DELETE", Path: "/download/:name", Export: true,
+			EntityType: reflect.TypeOf(DownloadRequestEntity{}),
+			Fields:     entityFields(DownloadRequestEntity{})},
+	}}
+	src, err := GenerateClient(desc, ClientOptions{Package: "users"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the client is built inside this module so that it imports this
+	// version of rubik, only the exported types of rubik can be used as
+	// the test files are not part of the package it builds
+	dir, err := ioutil.TempDir(".", "clientgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "users.go"), src, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command(goTool, "build", "./"+filepath.Base(dir)).CombinedOutput()
+	if err != nil {
+		t.Errorf("generated client does not build: %v\n%s\n%s", err, out, src)
+	}
+}
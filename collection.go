@@ -0,0 +1,370 @@
+package rubik
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rubikorg/blocks/ds"
+)
+
+// PostmanSchema is the schema of the Postman collections exported
+const PostmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// configBaseURL returns the URL of the server from the host and port of
+// the loaded config
+func configBaseURL(config ds.NotationMap) string {
+	host := "localhost"
+	if h := fmt.Sprint(config.Get("host")); config.Get("host") != nil && h != "" &&
+		h != "0.0.0.0" {
+		host = h
+	}
+
+	if port := config.Get("port"); port != nil && fmt.Sprint(port) != "" {
+		return fmt.Sprintf("http://%s:%v", host, port)
+	}
+	return "http://" + host
+}
+
+// exampleValue returns an example value of the Go type which is used in
+// the requests of the collections
+func exampleValue(t reflect.Type, key string, depth int) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return key
+	case reflect.Bool:
+		return false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 1
+	case reflect.Float32, reflect.Float64:
+		return 1.5
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 || depth > 3 {
+			return []interface{}{}
+		}
+		return []interface{}{exampleValue(t.Elem(), key, depth+1)}
+	case reflect.Map:
+		if depth > 3 {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{"key": exampleValue(t.Elem(), key, depth+1)}
+	case reflect.Struct:
+		if t == timeType {
+			return "2021-01-01T00:00:00Z"
+		}
+
+		obj := make(map[string]interface{})
+		if depth > 3 {
+			return obj
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if f.PkgPath != "" || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			obj[name] = exampleValue(f.Type, name, depth+1)
+		}
+		return obj
+	}
+	return nil
+}
+
+// fieldExample returns the example value of an entity field
+func fieldExample(f FieldInfo) interface{} {
+	if f.GoType == nil {
+		return exampleValue(reflect.TypeOf(""), f.Key, 0)
+	}
+	return exampleValue(f.GoType, f.Key, 0)
+}
+
+// exampleString returns the example of the field as it is written in a
+// path, query or form
+func exampleString(f FieldInfo) string {
+	switch v := fieldExample(f).(type) {
+	case string:
+		return v
+	case []interface{}, map[string]interface{}:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// exampleRequest is a route with the example values placed where inject
+// reads them from: form fields are sent in the query when the route also
+// has body fields
+type exampleRequest struct {
+	route  APIRoute
+	path   string
+	params map[string]string
+	query  []FieldInfo
+	body   []FieldInfo
+	form   []FieldInfo
+	files  bool
+}
+
+func newExampleRequest(r APIRoute) exampleRequest {
+	er := exampleRequest{route: r, params: make(map[string]string)}
+	_, names := openAPIPath(r.Path)
+	for _, name := range names {
+		er.params[name] = name
+	}
+
+	for _, f := range r.Fields {
+		switch f.In {
+		case "param":
+			for _, name := range names {
+				if strings.EqualFold(name, f.Key) {
+					er.params[name] = exampleString(f)
+				}
+			}
+		case "body":
+			er.body = append(er.body, f)
+		case "form":
+			er.form = append(er.form, f)
+			er.files = er.files || isFileField(f)
+		default:
+			er.query = append(er.query, f)
+		}
+	}
+
+	if len(er.body) > 0 {
+		er.query = append(er.query, er.form...)
+		er.form, er.files = nil, false
+	}
+
+	i := 0
+	er.path = paramSyntax.ReplaceAllStringFunc(r.Path, func(string) string {
+		p := url.PathEscape(er.params[names[i]])
+		i++
+		return p
+	})
+	return er
+}
+
+func isFileField(f FieldInfo) bool {
+	t := f.GoType
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == fileType
+}
+
+// bodyJSON returns the indented example of the JSON body
+func (er exampleRequest) bodyJSON() string {
+	body := make(map[string]interface{})
+	for _, f := range er.body {
+		body[f.Key] = fieldExample(f)
+	}
+	b, _ := json.MarshalIndent(body, "", "  ")
+	return string(b)
+}
+
+// queryString returns the encoded example query
+func (er exampleRequest) queryString(fields []FieldInfo) string {
+	var parts []string
+	for _, f := range fields {
+		parts = append(parts, url.QueryEscape(f.Key)+"="+url.QueryEscape(exampleString(f)))
+	}
+	return strings.Join(parts, "&")
+}
+
+// routeGroups returns the routes grouped by the router they belong to,
+// the routes of the root router come first
+func routeGroups(desc APIDescription) ([]string, map[string][]APIRoute) {
+	groups := make(map[string][]APIRoute)
+	var names []string
+	for _, r := range desc.Routes {
+		if _, ok := groups[r.Router]; !ok {
+			names = append(names, r.Router)
+		}
+		groups[r.Router] = append(groups[r.Router], r)
+	}
+	sort.Strings(names)
+	return names, groups
+}
+
+func requestName(r APIRoute) string {
+	if r.Description != "" {
+		return r.Description
+	}
+	return r.Method + " " + r.Path
+}
+
+// postmanRequest returns the Postman item of the route
+func postmanRequest(r APIRoute) map[string]interface{} {
+	er := newExampleRequest(r)
+
+	u := map[string]interface{}{
+		"host": []string{"{{baseUrl}}"},
+		"path": strings.Split(strings.Trim(paramSyntax.ReplaceAllString(r.Path, ":$1"), "/"), "/"),
+	}
+	raw := "{{baseUrl}}" + paramSyntax.ReplaceAllString(r.Path, ":$1")
+
+	if len(er.query) > 0 {
+		var query []map[string]interface{}
+		for _, f := range er.query {
+			query = append(query, map[string]interface{}{
+				"key": f.Key, "value": exampleString(f), "disabled": !f.Required,
+			})
+		}
+		u["query"] = query
+		raw += "?" + er.queryString(er.query)
+	}
+
+	_, names := openAPIPath(r.Path)
+	if len(names) > 0 {
+		var vars []map[string]string
+		for _, name := range names {
+			vars = append(vars, map[string]string{"key": name, "value": er.params[name]})
+		}
+		u["variable"] = vars
+	}
+	u["raw"] = raw
+
+	req := map[string]interface{}{
+		"method": r.Method,
+		"url":    u,
+		"header": []interface{}{},
+	}
+	if r.Description != "" {
+		req["description"] = r.Description
+	}
+
+	switch {
+	case len(er.body) > 0:
+		req["header"] = []map[string]string{{"key": Content.Header, "value": Content.JSON}}
+		req["body"] = map[string]interface{}{
+			"mode":    "raw",
+			"raw":     er.bodyJSON(),
+			"options": map[string]interface{}{"raw": map[string]string{"language": "json"}},
+		}
+	case len(er.form) > 0:
+		mode := "urlencoded"
+		if er.files {
+			mode = "formdata"
+		}
+
+		var form []map[string]interface{}
+		for _, f := range er.form {
+			if isFileField(f) {
+				form = append(form, map[string]interface{}{"key": f.Key, "type": "file", "src": ""})
+				continue
+			}
+			form = append(form, map[string]interface{}{
+				"key": f.Key, "value": exampleString(f), "type": "text",
+			})
+		}
+		req["body"] = map[string]interface{}{"mode": mode, mode: form}
+	}
+
+	return map[string]interface{}{"name": requestName(r), "request": req}
+}
+
+// PostmanCollection returns the Postman v2.1 collection of the routes
+// with a folder per router, the requests use the baseUrl variable of the
+// collection which is set to baseURL
+func PostmanCollection(desc APIDescription, baseURL string) map[string]interface{} {
+	name := desc.Service
+	if name == "" {
+		name = "Rubik API"
+	}
+
+	var items []interface{}
+	names, groups := routeGroups(desc)
+	for _, router := range names {
+		var requests []interface{}
+		for _, r := range groups[router] {
+			requests = append(requests, postmanRequest(r))
+		}
+
+		if router == "" {
+			items = append(items, requests...)
+			continue
+		}
+
+		folder := map[string]interface{}{"name": router, "item": requests}
+		if d := desc.Routers[router]; d != "" {
+			folder["description"] = d
+		}
+		items = append(items, folder)
+	}
+
+	return map[string]interface{}{
+		"info":     map[string]string{"name": name, "schema": PostmanSchema},
+		"variable": []map[string]string{{"key": "baseUrl", "value": baseURL}},
+		"item":     items,
+	}
+}
+
+// httpBoundary separates the parts of the multipart requests
+const httpBoundary = "RubikFormBoundary"
+
+// HTTPFile returns the routes as a .http file of the REST client of VS
+// Code with a section per router
+func HTTPFile(desc APIDescription, baseURL string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "@baseUrl = %s\n", baseURL)
+
+	names, groups := routeGroups(desc)
+	for _, router := range names {
+		if router != "" {
+			fmt.Fprintf(&buf, "\n# %s", router)
+			if d := desc.Routers[router]; d != "" {
+				fmt.Fprintf(&buf, ": %s", d)
+			}
+			buf.WriteString("\n")
+		}
+
+		for _, r := range groups[router] {
+			writeHTTPRequest(&buf, r)
+		}
+	}
+	return buf.Bytes()
+}
+
+func writeHTTPRequest(buf *bytes.Buffer, r APIRoute) {
+	er := newExampleRequest(r)
+	fmt.Fprintf(buf, "\n### %s\n", requestName(r))
+	fmt.Fprintf(buf, "%s {{baseUrl}}%s", r.Method, er.path)
+	if len(er.query) > 0 {
+		buf.WriteString("?" + er.queryString(er.query))
+	}
+	buf.WriteString("\n")
+
+	switch {
+	case len(er.body) > 0:
+		fmt.Fprintf(buf, "%s: %s\n\n%s\n", Content.Header, Content.JSON, er.bodyJSON())
+	case er.files:
+		fmt.Fprintf(buf, "%s: %s; boundary=%s\n\n", Content.Header, Content.Multipart,
+			httpBoundary)
+		for _, f := range er.form {
+			fmt.Fprintf(buf, "--%s\n", httpBoundary)
+			if isFileField(f) {
+				fmt.Fprintf(buf, "Content-Disposition: form-data; name=%q; filename=%q\n\n< ./%s\n",
+					f.Key, f.Key, f.Key)
+				continue
+			}
+			fmt.Fprintf(buf, "Content-Disposition: form-data; name=%q\n\n%s\n", f.Key,
+				exampleString(f))
+		}
+		fmt.Fprintf(buf, "--%s--\n", httpBoundary)
+	case len(er.form) > 0:
+		fmt.Fprintf(buf, "%s: %s\n\n%s\n", Content.Header, Content.URLEncoded,
+			er.queryString(er.form))
+	}
+}
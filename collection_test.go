@@ -0,0 +1,109 @@
+package rubik
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rubikorg/blocks/ds"
+)
+
+type collectionUpload struct {
+	Entity
+	Avatar File   `rubik:"avatar|form!"`
+	Note   string `rubik:"note|form"`
+}
+
+func collectionAPI() APIDescription {
+	tree := testRouteTree()
+	tree.RouterList = map[string]string{"users": "Manages the users"}
+	desc := describeAPI(tree)
+	desc.Routes = append(desc.Routes, APIRoute{Method: "POST", Path: "/upload",
+		EntityType: reflect.TypeOf(collectionUpload{}), Fields: entityFields(collectionUpload{})})
+	return desc
+}
+
+func TestConfigBaseURL(t *testing.T) {
+	nm := ds.NewNotationMap()
+	nm.Assign(map[string]interface{}{"host": "0.0.0.0", "port": 8000})
+	if u := configBaseURL(nm); u != "http://localhost:8000" {
+		t.Error("configBaseURL() returned", u)
+	}
+
+	nm.Assign(map[string]interface{}{"host": "api.local", "port": 8000})
+	if u := configBaseURL(nm); u != "http://api.local:8000" {
+		t.Error("configBaseURL() returned", u)
+	}
+}
+
+func TestPostmanCollection(t *testing.T) {
+	col := PostmanCollection(collectionAPI(), "http://localhost:8000")
+	b, err := json.Marshal(col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(b)
+
+	for _, want := range []string{
+		`"schema":"` + PostmanSchema + `"`,
+		`"variable":[{"key":"baseUrl","value":"http://localhost:8000"}]`,
+		`"description":"Manages the users","item":[`,
+		`"raw":"{{baseUrl}}/users/:id?limit=1"`,
+		`"variable":[{"key":"id","value":"1"}]`,
+		`"query":[{"disabled":true,"key":"limit","value":"1"}]`,
+		`"raw":"{\n  \"name\": \"name\"\n}"`,
+		`"mode":"formdata"`,
+		`{"key":"avatar","src":"","type":"file"}`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("PostmanCollection() does not contain %s\n%s", want, code)
+		}
+	}
+
+	items := col["item"].([]interface{})
+	if len(items) != 3 || items[2].(map[string]interface{})["name"] != "users" {
+		t.Error("PostmanCollection() did not group the routes by router:", items)
+	}
+}
+
+func TestHTTPFile(t *testing.T) {
+	code := string(HTTPFile(collectionAPI(), "http://localhost:8000"))
+	for _, want := range []string{
+		"@baseUrl = http://localhost:8000\n",
+		"\n### Index\nGET {{baseUrl}}/\n",
+		"\n# users: Manages the users\n",
+		"\n### Updates a user\nPOST {{baseUrl}}/users/1?limit=1\n" +
+			"Content-Type: application/json\n\n{\n  \"name\": \"name\"\n}\n",
+		"\n### Gets a user\nGET {{baseUrl}}/users/id\n",
+		"Content-Type: multipart/form-data; boundary=RubikFormBoundary\n\n" +
+			"--RubikFormBoundary\nContent-Disposition: form-data; name=\"avatar\"; " +
+			"filename=\"avatar\"\n\n< ./avatar\n",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("HTTPFile() does not contain %q\n%s", want, code)
+		}
+	}
+}
+
+func TestExportPluginCollections(t *testing.T) {
+	var buf bytes.Buffer
+	ep := &ExportPlugin{format: "http", base: "http://api.local", out: &buf}
+	if err := ep.OnPlug(&App{RouteTree: testRouteTree()}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "@baseUrl = http://api.local\n") {
+		t.Error("ExportPlugin did not write the .http file:", buf.String())
+	}
+
+	buf.Reset()
+	ep.format = "postman"
+	ep.exported = true
+	if err := ep.OnPlug(&App{RouteTree: testRouteTree()}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), PostmanSchema) || strings.Contains(buf.String(), "/users") {
+		t.Error("ExportPlugin did not write the exported routes:", buf.String())
+	}
+}
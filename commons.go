@@ -46,7 +46,7 @@ func substituteParam(path string, reqParams []string) (string, error) {
 	// we need a replaced path
 	if len(reqParams) > 0 {
 		for _, param := range reqParams {
-			pathWithParams = strings.Replace(pathWithParams, "$", param, -1)
+			pathWithParams = strings.Replace(pathWithParams, "$", param, 1)
 		}
 	}
 
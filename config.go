@@ -0,0 +1,335 @@
+package rubik
+
+import (
+	"bytes"
+	"flag"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"github.com/rubikorg/blocks/ds"
+	"github.com/rubikorg/rubik/pkg"
+)
+
+// configMu guards the config of rubik which is swapped on reload
+var configMu sync.RWMutex
+
+// overrideValues are the config overrides given with OverrideConfig or
+// the flag registered by ConfigSetFlag, guarded by overrideMu
+var (
+	overrideValues = make(map[string]string)
+	overrideMu     sync.Mutex
+)
+
+// OverrideConfig overrides the dotted key of the config with the raw value,
+// it is applied over the config files and the env variables by Load and
+// on every reload
+//
+// 		rubik.OverrideConfig("database.port", "5433")
+func OverrideConfig(key, value string) {
+	overrideMu.Lock()
+	defer overrideMu.Unlock()
+	overrideValues[key] = value
+}
+
+// setFlag is the flag.Value of the repeatable -set flag
+type setFlag struct{}
+
+func (setFlag) String() string { return "" }
+
+func (setFlag) Set(kv string) error {
+	key, value, err := pkg.ParseOverride(kv)
+	if err != nil {
+		return err
+	}
+	OverrideConfig(key, value)
+	return nil
+}
+
+// ConfigSetFlag registers the repeatable `-set key=value` flag on the flag
+// set which overrides the keys of the config like OverrideConfig. Parse the
+// flags before calling Load:
+//
+// 		rubik.ConfigSetFlag(flag.CommandLine)
+// 		flag.Parse()
+// 		err := rubik.Load(&config)
+func ConfigSetFlag(fs *flag.FlagSet) {
+	fs.Var(setFlag{}, "set", "override a config key, `key=value`")
+}
+
+// configOverrides returns a copy of the overrides given with OverrideConfig
+func configOverrides() map[string]string {
+	overrideMu.Lock()
+	defer overrideMu.Unlock()
+	overrides := make(map[string]string, len(overrideValues))
+	for k, v := range overrideValues {
+		overrides[k] = v
+	}
+	return overrides
+}
+
+// notationMap returns the flattened config of rubik
+func (r *rubik) notationMap() ds.NotationMap {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return r.intermConfig
+}
+
+// ConfigSource is used by Load to read the config of an environment.
+// Read returns a nil map if the source has no config for the env and
+// the location from where the config was read, which is used in the
+// error messages of Load
+type ConfigSource interface {
+	Read(env string) (map[string]interface{}, string, error)
+}
+
+// FileSource reads the config of an env from the file named after the env
+// inside Dir. The file can be a .toml, .json, .yaml, .yml or .env file and
+// is looked up in this order. When FS is set, the files are read from FS
+// instead of the disk which lets you ship your configs inside the binary:
+//
+// 		//go:embed config
+// 		var configs embed.FS
+//
+// 		rubik.UseConfigSource(rubik.FileSource{FS: configs, Dir: "config"})
+type FileSource struct {
+	Dir string
+	FS  fs.FS
+}
+
+// Read implements the ConfigSource interface
+func (fsrc FileSource) Read(env string) (map[string]interface{}, string, error) {
+	for _, ext := range pkg.ConfigExtensions {
+		var p string
+		var b []byte
+		var err error
+		if fsrc.FS != nil {
+			p = path.Join(fsrc.Dir, env+ext)
+			b, err = fs.ReadFile(fsrc.FS, p)
+		} else {
+			p = filepath.Join(fsrc.Dir, env+ext)
+			b, err = ioutil.ReadFile(p)
+		}
+
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, p, errors.WithStack(err)
+		}
+
+		m, err := pkg.DecodeConfig(ext, b)
+		if err != nil {
+			return nil, p, errors.Wrapf(err, "ConfigDecodeError: cannot decode %s", p)
+		}
+		return m, p, nil
+	}
+
+	return nil, "", nil
+}
+
+var configSources []ConfigSource
+var configDir = ""
+
+// UseConfigSource replaces the sources from which Load reads your config.
+// The configs of all the sources are merged in the order they are given.
+// By default the config is read from the FileSource of the config
+// directory.
+func UseConfigSource(sources ...ConfigSource) {
+	configSources = sources
+}
+
+// SetConfigDir sets the directory from which the default FileSource reads
+// your config files. It defaults to the RUBIK_CONFIG_DIR env variable or
+// ./config if it is not set
+func SetConfigDir(dir string) {
+	configDir = dir
+}
+
+func getConfigSources() []ConfigSource {
+	if len(configSources) > 0 {
+		return configSources
+	}
+
+	dir := configDir
+	if dir == "" {
+		dir = os.Getenv("RUBIK_CONFIG_DIR")
+	}
+	if dir == "" {
+		dir = filepath.Join(".", "config")
+	}
+	return []ConfigSource{FileSource{Dir: dir}}
+}
+
+// configFile is a config read from a ConfigSource
+type configFile struct {
+	path   string
+	values map[string]interface{}
+}
+
+// readConfigFiles reads the default and env configs from all the config
+// sources in the order in which they must be merged: the default configs
+// of all the sources and then their env configs
+func readConfigFiles(env string) ([]configFile, bool, error) {
+	envs := []string{"default"}
+	if env != "default" {
+		envs = append(envs, env)
+	}
+
+	var files []configFile
+	envFound := false
+	for _, e := range envs {
+		for _, src := range getConfigSources() {
+			m, p, err := src.Read(e)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if m == nil {
+				continue
+			}
+
+			if e != "default" {
+				envFound = true
+			}
+			files = append(files, configFile{path: p, values: m})
+		}
+	}
+
+	return files, envFound, nil
+}
+
+var strictConfig = false
+
+// StrictConfig makes Load report the keys of your config which do not
+// match any field of your config struct, useful for finding misspelled keys
+func StrictConfig(strict bool) {
+	strictConfig = strict
+}
+
+// configOrigins maps every dotted key of the config to the place it was
+// last set at, used for reporting the problems of the config
+func configOrigins(files []configFile, envOverrides,
+	setOverrides map[string]string) map[string]string {
+	origins := make(map[string]string)
+	for _, f := range files {
+		for _, k := range pkg.FlattenKeys(f.values) {
+			origins[k] = f.path
+		}
+	}
+
+	for k := range envOverrides {
+		origins[k] = "env " + ConfigEnvPrefix +
+			strings.ToUpper(strings.ReplaceAll(k, ".", "__"))
+	}
+
+	for k := range setOverrides {
+		origins[k] = "-set " + k
+	}
+	return origins
+}
+
+// validateConfig validates the merged config against the struct pointed
+// by target, any other target is not validated
+func validateConfig(target interface{}, values map[string]interface{},
+	origins map[string]string, secrets []string) error {
+	t := reflect.TypeOf(target).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	schema := pkg.ConfigSchema{Origins: origins, Strict: strictConfig, Secrets: secrets}
+	problems := schema.Validate(t, values)
+	if len(problems) > 0 {
+		return pkg.ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// mergeConfig reads the config files of the env and merges them along with
+// the overrides which are converted to the types of the fields of the
+// config struct t. It returns false if there is no config at all
+func mergeConfig(env string, t reflect.Type) (map[string]interface{}, map[string]string,
+	bool, error) {
+	// overrides are applied over the config files in the order of
+	// env variables and then the -set flags
+	envOverrides := pkg.EnvOverrides(ConfigEnvPrefix, os.Environ())
+	setOverrides := configOverrides()
+
+	files, envConfigFound, err := readConfigFiles(env)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if env != "default" && !envConfigFound {
+		app.logger.Debug("ConfigNotFound: config file does not exist", "env", env)
+	}
+
+	if len(files) == 0 && len(envOverrides) == 0 && len(setOverrides) == 0 {
+		return nil, nil, false, nil
+	}
+
+	// now we need to merge the env config values over the default values
+	values := make(map[string]interface{})
+	for _, f := range files {
+		values = pkg.MergeValues(values, f.values)
+	}
+
+	pkg.ApplyOverrides(values, envOverrides, t)
+	pkg.ApplyOverrides(values, setOverrides, t)
+
+	return values, configOrigins(files, envOverrides, setOverrides), true, nil
+}
+
+// decodeConfig validates the merged config values and decodes them into
+// the config pointer. The secrets of the values must be resolved with
+// pkg.ResolveSecrets before, secrets are the keys it returned. It returns
+// the flattened NotationMap of the values
+func decodeConfig(config interface{}, values map[string]interface{},
+	origins map[string]string, secrets []string) (ds.NotationMap, error) {
+	nm := ds.NewNotationMap()
+	err := validateConfig(config, values, origins, secrets)
+	if err != nil {
+		return nm, err
+	}
+
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	err = enc.Encode(&values)
+	if err != nil {
+		return nm, errors.WithStack(err)
+	}
+
+	err = toml.Unmarshal(buf.Bytes(), config)
+	if err != nil {
+		return nm, errors.WithStack(err)
+	}
+
+	nm.Assign(values)
+	// irrespective of env found or not flatten the intermConfig
+	if nm.Length() > 0 {
+		nm.Flatten()
+	}
+	// before loading anything to interm config mark notation map as not editable
+	nm.IsEditable(false)
+
+	return nm, nil
+}
+
+// RedactedConfig returns the merged config values of your app where the
+// values resolved from ${file:...} and ${env:...} secrets are redacted.
+// Use it whenever you need to print or log your config
+func RedactedConfig() map[string]interface{} {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	if app.configMap == nil {
+		return make(map[string]interface{})
+	}
+	return pkg.RedactValues(app.configMap, app.secretKeys)
+}
@@ -0,0 +1,99 @@
+package rubik
+
+import (
+	"flag"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileSource(t *testing.T) {
+	src := FileSource{
+		Dir: "config",
+		FS: fstest.MapFS{
+			"config/default.toml":    {Data: []byte("port = \"8000\"\n")},
+			"config/production.json": {Data: []byte(`{"port": "80"}`)},
+		},
+	}
+
+	m, p, err := src.Read("production")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p != "config/production.json" || m["port"] != "80" {
+		t.Errorf("FileSource did not read the JSON config. path: %s config: %v", p, m)
+	}
+
+	m, _, err = src.Read("staging")
+	if err != nil || m != nil {
+		t.Error("FileSource returned a config for env without a config file")
+	}
+}
+
+func TestReadConfigFiles(t *testing.T) {
+	defer UseConfigSource()
+
+	UseConfigSource(FileSource{
+		FS: fstest.MapFS{
+			"default.toml": {Data: []byte("port = \"8000\"\n")},
+			"staging.yaml": {Data: []byte("port: \"9000\"\n")},
+		},
+	})
+
+	files, envFound, err := readConfigFiles("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !envFound || len(files) != 2 || files[1].values["port"] != "9000" {
+		t.Error("readConfigFiles() did not read default and env configs in order:", files)
+	}
+}
+
+type overrideTestConfig struct {
+	Port string `toml:"port"`
+	DB   struct {
+		Pass string `toml:"pass"`
+	} `toml:"db"`
+	Limit int `toml:"limit"`
+}
+
+func TestLoadOverrides(t *testing.T) {
+	oldEnv, oldConfig, oldMap := app.currentEnv, app.config, app.configMap
+	oldType, oldNM, oldSecrets := app.configType, app.intermConfig, app.secretKeys
+	defer func() {
+		app.currentEnv, app.config, app.configMap = oldEnv, oldConfig, oldMap
+		app.configType, app.intermConfig, app.secretKeys = oldType, oldNM, oldSecrets
+		overrideValues = make(map[string]string)
+		UseConfigSource()
+	}()
+
+	UseConfigSource(FileSource{
+		FS: fstest.MapFS{
+			"default.toml": {Data: []byte("port = \"8000\"\nlimit = 10\n")},
+		},
+	})
+	os.Setenv("RUBIK_CFG_DB__PASS", "12345")
+	defer os.Unsetenv("RUBIK_CFG_DB__PASS")
+
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	ConfigSetFlag(fs)
+	err := fs.Parse([]string{"-set", "limit=25", "-set=port=9000", "--", "-set", "port=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conf overrideTestConfig
+	if err := Load(&conf); err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.DB.Pass != "12345" || conf.Limit != 25 || conf.Port != "9000" {
+		t.Errorf("Load() did not apply the overrides. config: %+v", conf)
+	}
+
+	if err := fs.Parse([]string{"-set", "limit"}); err == nil {
+		t.Error("-set without a value did not fail")
+	}
+}
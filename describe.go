@@ -0,0 +1,163 @@
+package rubik
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// APIDescription describes the routes of your rubik server, it is written
+// by the export plugin and used by the tools which generate clients and
+// documentation of your API
+type APIDescription struct {
+	Service string            `json:"service"`
+	Routers map[string]string `json:"routers,omitempty"`
+	Routes  []APIRoute        `json:"routes"`
+}
+
+// APIRoute describes a single route and the fields of its entity
+type APIRoute struct {
+	Method        string               `json:"method"`
+	Path          string               `json:"path"`
+	Router        string               `json:"router"`
+	Description   string               `json:"description"`
+	Entity        string               `json:"entity,omitempty"`
+	JSON          bool                 `json:"json,omitempty"`
+	Export        bool                 `json:"export,omitempty"`
+	Fields        []FieldInfo          `json:"fields,omitempty"`
+	Responses     map[int]string       `json:"responses,omitempty"`
+	ResponseTypes map[int]reflect.Type `json:"-"`
+	EntityType    reflect.Type         `json:"-"`
+}
+
+// FieldInfo describes a field of a route entity. In is the transport of
+// the field: query, body, form or param and Type is the JSON type of
+// the field: string, integer, number, boolean, array or object
+type FieldInfo struct {
+	Name     string       `json:"name"`
+	Key      string       `json:"key"`
+	In       string       `json:"in"`
+	Type     string       `json:"type"`
+	Required bool         `json:"required"`
+	GoType   reflect.Type `json:"-"`
+}
+
+// jsonType returns the JSON type of a Go type
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
+
+// entityFields returns the fields of the entity which are injected from
+// the request
+func entityFields(en interface{}) []FieldInfo {
+	if en == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(en)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []FieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Name == "Entity" || field.PkgPath != "" {
+			continue
+		}
+
+		key, in, required := parseEntityTag(field)
+		fields = append(fields, FieldInfo{
+			Name:     field.Name,
+			Key:      key,
+			In:       in,
+			Type:     jsonType(field.Type),
+			Required: required,
+			GoType:   field.Type,
+		})
+	}
+	return fields
+}
+
+// routeMethods returns the HTTP methods of the route, a route can be
+// booted for many methods like "GET|POST" and routes without a method
+// are booted as GET routes
+func routeMethods(method string) []string {
+	if method == "" {
+		return []string{"GET"}
+	}
+
+	var methods []string
+	for _, m := range strings.Split(method, "|") {
+		if m = strings.ToUpper(strings.TrimSpace(m)); m != "" {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// describeAPI returns the APIDescription of the routes inside the tree
+// sorted by path and method so that the output is stable between runs
+func describeAPI(tree RouteTree) APIDescription {
+	desc := APIDescription{Service: app.currentService, Routers: tree.RouterList,
+		Routes: []APIRoute{}}
+	for _, r := range tree.Routes {
+		route := APIRoute{
+			Path:        r.FullPath,
+			Router:      r.BelongsTo,
+			Description: r.Description,
+			JSON:        r.IsJSON,
+			Export:      r.Export,
+			Fields:      entityFields(r.Entity),
+			Responses:   r.Responses,
+		}
+
+		if len(r.ResponseTypes) > 0 {
+			route.ResponseTypes = make(map[int]reflect.Type)
+			for status, v := range r.ResponseTypes {
+				if v != nil {
+					route.ResponseTypes[status] = reflect.TypeOf(v)
+				}
+			}
+		}
+
+		if r.Entity != nil {
+			route.EntityType = reflect.TypeOf(r.Entity)
+			route.Entity = route.EntityType.Name()
+		}
+
+		// a route booted for many methods is described once per method
+		for _, m := range routeMethods(r.Method) {
+			route.Method = m
+			desc.Routes = append(desc.Routes, route)
+		}
+	}
+
+	sort.SliceStable(desc.Routes, func(i, j int) bool {
+		if desc.Routes[i].Path != desc.Routes[j].Path {
+			return desc.Routes[i].Path < desc.Routes[j].Path
+		}
+		return desc.Routes[i].Method < desc.Routes[j].Method
+	})
+	return desc
+}
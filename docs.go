@@ -0,0 +1,135 @@
+package rubik
+
+import (
+	"bytes"
+	"embed"
+	html "html/template"
+	"os"
+	"strings"
+	"sync"
+)
+
+//go:embed assets/docs.html
+var assets embed.FS
+
+// DocsOptions configures the API documentation page of your server.
+//
+// The page lists the routes with their entity fields and declared
+// responses and lets you try them from the browser. All the assets are
+// embedded so the page works offline. Path is the route of the page under
+// /rubik, /rubik/docs by default. The page is not served when RUBIK_ENV
+// is production unless EnableInProduction is set.
+type DocsOptions struct {
+	Title              string
+	Path               string
+	ExportedOnly       bool
+	EnableInProduction bool
+}
+
+const defaultDocsPath = "/rubik/docs"
+
+var docsOpts *DocsOptions
+
+// ServeDocs serves the API documentation page of your routes
+//
+// 		rubik.ServeDocs(rubik.DocsOptions{Title: "Users API"})
+func ServeDocs(opts DocsOptions) {
+	if opts.Path == "" {
+		opts.Path = defaultDocsPath
+	}
+	if !strings.HasPrefix(opts.Path, "/rubik/") {
+		opts.Path = "/rubik" + safeRoutePath(opts.Path)
+	}
+	docsOpts = &opts
+}
+
+// isProduction returns true if rubik is running in the production env
+func isProduction() bool {
+	env := strings.ToLower(os.Getenv("RUBIK_ENV"))
+	return env == "production" || env == "prod"
+}
+
+// docsEnabled returns true if the docs page must be booted
+func docsEnabled() bool {
+	return docsOpts != nil && (docsOpts.EnableInProduction || !isProduction())
+}
+
+// docsPage is the data of the docs template
+type docsPage struct {
+	Title  string
+	Routes []APIRoute
+}
+
+// renderDocs returns the HTML of the docs page of the routes inside tree
+func renderDocs(tree RouteTree, opts DocsOptions) ([]byte, error) {
+	tmpl, err := html.ParseFS(assets, "assets/docs.html")
+	if err != nil {
+		return nil, err
+	}
+
+	desc := describeAPI(tree)
+	page := docsPage{Title: opts.Title, Routes: []APIRoute{}}
+	if page.Title == "" {
+		page.Title = desc.Service
+	}
+	if page.Title == "" {
+		page.Title = "Rubik API"
+	}
+
+	for _, r := range desc.Routes {
+		if opts.ExportedOnly && !r.Export {
+			continue
+		}
+		page.Routes = append(page.Routes, withPathParams(r))
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// withPathParams adds the path parameters which are not part of the entity
+// to the fields of the route as strings so that the try it form has an
+// input for every parameter of the path
+func withPathParams(r APIRoute) APIRoute {
+	declared := make(map[string]bool)
+	for _, f := range r.Fields {
+		if f.In == "param" {
+			declared[f.Key] = true
+		}
+	}
+
+	_, names := openAPIPath(r.Path)
+	var params []FieldInfo
+	for _, name := range names {
+		if !declared[name] {
+			params = append(params, FieldInfo{Name: name, Key: name, In: "param",
+				Type: "string", Required: true})
+		}
+	}
+
+	if len(params) > 0 {
+		r.Fields = append(params, r.Fields...)
+	}
+	return r
+}
+
+var docsHTML []byte
+var docsErr error
+var docsOnce sync.Once
+
+// docsController serves the docs page which is rendered once as the
+// routes do not change after boot
+func docsController(req *Request) {
+	docsOnce.Do(func() {
+		docsHTML, docsErr = renderDocs(req.app.routeTree, *docsOpts)
+	})
+
+	if docsErr != nil {
+		req.Throw(500, docsErr)
+		return
+	}
+	writeResponse(&req.Writer, 200, Content.HTML+"; charset=utf-8", docsHTML)
+}
@@ -0,0 +1,59 @@
+package rubik
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRenderDocs(t *testing.T) {
+	tree := testRouteTree()
+	tree.Routes[0].Responses = map[int]string{404: "User not found"}
+
+	b, err := renderDocs(tree, DocsOptions{Title: "Users API"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := string(b)
+	for _, want := range []string{"Users API", `data-path="/users/:id"`, "User not found",
+		`<span class="req" title="required">*</span>`, `data-in="body"`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("renderDocs() output does not contain %s", want)
+		}
+	}
+
+	// GET /users/:id has no entity but still needs an input for :id
+	if strings.Count(page, `<input name="id" data-in="param"`) != 2 {
+		t.Error("renderDocs() did not add an input for the path parameter without a field")
+	}
+
+	b, _ = renderDocs(tree, DocsOptions{ExportedOnly: true})
+	if strings.Contains(string(b), `class="try"`) {
+		t.Error("renderDocs() did not filter the exported routes")
+	}
+}
+
+func TestDocsEnabled(t *testing.T) {
+	defer func() { docsOpts = nil }()
+	defer os.Setenv("RUBIK_ENV", os.Getenv("RUBIK_ENV"))
+
+	if docsEnabled() {
+		t.Error("docsEnabled() returned true without ServeDocs")
+	}
+
+	ServeDocs(DocsOptions{Path: "api"})
+	if docsOpts.Path != "/rubik/api" {
+		t.Error("ServeDocs() did not put the page under /rubik:", docsOpts.Path)
+	}
+
+	os.Setenv("RUBIK_ENV", "production")
+	if docsEnabled() {
+		t.Error("docsEnabled() returned true in production")
+	}
+
+	docsOpts.EnableInProduction = true
+	if !docsEnabled() {
+		t.Error("docsEnabled() returned false with EnableInProduction")
+	}
+}
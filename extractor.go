@@ -2,6 +2,7 @@ package rubik
 
 import (
 	"bytes"
+	"encoding/gob"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -37,6 +38,8 @@ func extract(en interface{}) (Payload, error) {
 	values := reflect.ValueOf(en)
 
 	num := fields.NumField()
+	// the Entity field can be embedded or named so its index is kept
+	entityIdx := -1
 
 	for i := 0; i < num; i++ {
 		field := fields.Field(i)
@@ -45,12 +48,14 @@ func extract(en interface{}) (Payload, error) {
 
 		// Get route value from Entity
 		if (field.Type == reflect.TypeOf(Entity{})) {
+			entityIdx = i
 			route := value.FieldByName("PointTo").String()
 			params, ok := value.FieldByName("Params").Interface().([]string)
 			if ok {
 				payload.params = params
 			}
 			isJSON := value.FieldByName("JSON").Bool()
+			payload.gob = value.FieldByName("Gob").Bool()
 			isURLEncoded := value.FieldByName("URLEncoded").Bool()
 			isFormData := value.FieldByName("FormData").Bool()
 
@@ -72,7 +77,8 @@ func extract(en interface{}) (Payload, error) {
 		}
 
 		var op = strings.ReplaceAll(tag, "?", "")
-		op = strings.ReplaceAll(tag, "*", "")
+		op = strings.ReplaceAll(op, "*", "")
+		op = strings.ReplaceAll(op, "!", "")
 		var transportKey = unCapitalize(field.Name)
 		var transport = "query"
 		if strings.Contains(op, "|") {
@@ -85,6 +91,11 @@ func extract(en interface{}) (Payload, error) {
 			}
 			transportKey = reqTag[0]
 			transport = reqTag[1]
+		} else if isOneOf(op, "query", "body", "form", "param") {
+			// same as the server, a single word is the transport or the key
+			transport = op
+		} else if op != "" {
+			transportKey = op
 		}
 
 		switch transport {
@@ -95,7 +106,13 @@ func extract(en interface{}) (Payload, error) {
 				payload.body = Values{}
 			}
 
-			exVal := values.Field(i).Elem().Interface()
+			exVal := value.Interface()
+			if value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+				if value.IsNil() {
+					continue
+				}
+				exVal = value.Elem().Interface()
+			}
 			strVal, ok := exVal.(string)
 			if !ok {
 				payload.body.Set(transportKey, exVal)
@@ -150,6 +167,20 @@ func extract(en interface{}) (Payload, error) {
 
 	payload.formBody = body
 
+	// gob sends the whole entity without the Entity which holds the
+	// options of the request
+	if payload.gob {
+		gobEn := reflect.New(fields).Elem()
+		gobEn.Set(values)
+		gobEn.Field(entityIdx).Set(reflect.Zero(reflect.TypeOf(Entity{})))
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(gobEn.Interface()); err != nil {
+			return Payload{}, err
+		}
+		payload.rawBody = buf.Bytes()
+	}
+
 	return payload, nil
 }
 
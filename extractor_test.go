@@ -0,0 +1,73 @@
+package rubik
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+)
+
+type extractEn struct {
+	Entity
+	Name   string  `rubik:"name|body!"`
+	Note   string  `rubik:"body"`
+	Search string  `rubik:"q"`
+	Page   string  `rubik:"query"`
+	Parent *string `rubik:"parent|body"`
+}
+
+func TestExtractTags(t *testing.T) {
+	en := extractEn{Name: "rubik", Note: "hello", Search: "users", Page: "2"}
+	en.PointTo = "/users"
+
+	payload, err := extract(en)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.body["name"] != "rubik" || payload.body["note"] != "hello" {
+		t.Error("extract() did not read the body fields:", payload.body)
+	}
+
+	if _, ok := payload.body["parent"]; ok {
+		t.Error("extract() sent a nil pointer field:", payload.body)
+	}
+
+	if payload.query.Get("q") != "users" || payload.query.Get("page") != "2" {
+		t.Error("extract() did not read the query fields:", payload.query)
+	}
+}
+
+func TestSubstituteParam(t *testing.T) {
+	p, err := substituteParam("/users/$/files/$", []string{"1", "avatar.png"})
+	if err != nil || p != "/users/1/files/avatar.png" {
+		t.Error("substituteParam() returned", p, err)
+	}
+
+	if _, err := substituteParam("/users/$", nil); err == nil {
+		t.Error("substituteParam() did not fail for missing params")
+	}
+}
+
+type namedGobEn struct {
+	En   Entity
+	Name string `rubik:"name|body"`
+}
+
+func TestExtractGobNamedEntity(t *testing.T) {
+	en := namedGobEn{Name: "rubik"}
+	en.En.PointTo = "/users"
+	en.En.Gob = true
+
+	payload, err := extract(en)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded namedGobEn
+	if err := gob.NewDecoder(bytes.NewReader(payload.rawBody)).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Name != "rubik" || decoded.En.PointTo != "" {
+		t.Error("extract() did not send the entity without its Entity field:", decoded)
+	}
+}
@@ -0,0 +1,141 @@
+package rubik
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// ServeContent writes the content with support for byte ranges and the
+// conditional requests of If-Match, If-None-Match, If-Modified-Since and
+// If-Range using http.ServeContent. The Content-Type is taken from the
+// extension of the name or sniffed from the content unless it is set and
+// an ETag is made from the modtime and the size of the content unless it
+// is set. A zero modtime sends no Last-Modified header
+func (req *Request) ServeContent(name string, modtime time.Time, content io.ReadSeeker) {
+	h := req.Writer.Header()
+	if h.Get("ETag") == "" && !modtime.IsZero() {
+		size, err := content.Seek(0, io.SeekEnd)
+		if err == nil {
+			_, err = content.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			req.Throw(500, err)
+			return
+		}
+		h.Set("ETag", fmt.Sprintf("\"%x-%x\"", modtime.UnixNano(), size))
+	}
+
+	http.ServeContent(&req.Writer, req.Raw, name, modtime, content)
+}
+
+// SendFile sends the file at the path, responds with 404 if there is no
+// such file
+//
+// 		func avatar(req *rubik.Request) {
+// 			req.SendFile("assets/avatar.png")
+// 		}
+func (req *Request) SendFile(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		req.throwFileError(err)
+		return
+	}
+	defer f.Close()
+	req.sendFile(f)
+}
+
+// SendStoreFile sends a file of the FileStore, the file cannot be outside
+// of the FileStore so that the names given by the users can be served
+//
+// 		uploads, _ := rubik.Storage.Access("uploads")
+// 		req.SendStoreFile(uploads, req.Params.ByName("name"))
+func (req *Request) SendStoreFile(store FileStore, file string) {
+	f, err := store.Open(file)
+	if err != nil {
+		req.throwFileError(err)
+		return
+	}
+	defer f.Close()
+	req.sendFile(f)
+}
+
+// Attachment sends the content as a file to be downloaded with the name.
+// Byte ranges and conditional requests are supported when the content is
+// an io.ReadSeeker, the content is closed if it is an io.Closer
+func (req *Request) Attachment(name string, content io.Reader) {
+	if c, ok := content.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	req.Writer.Header().Set("Content-Disposition",
+		mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+
+	var modtime time.Time
+	if f, ok := content.(*os.File); ok {
+		if info, err := f.Stat(); err == nil {
+			modtime = info.ModTime()
+		}
+	}
+
+	if rs, ok := content.(io.ReadSeeker); ok {
+		req.ServeContent(name, modtime, rs)
+		return
+	}
+
+	h := req.Writer.Header()
+	br := bufio.NewReaderSize(content, 512)
+	if h.Get(Content.Header) == "" {
+		ctype := mime.TypeByExtension(filepath.Ext(name))
+		if ctype == "" {
+			head, _ := br.Peek(512)
+			ctype = http.DetectContentType(head)
+		}
+		h.Set(Content.Header, ctype)
+	}
+	req.Writer.WriteHeader(http.StatusOK)
+	io.Copy(&req.Writer, br)
+}
+
+func (req *Request) sendFile(f *os.File) {
+	info, err := f.Stat()
+	if err != nil {
+		req.throwFileError(err)
+		return
+	}
+	if info.IsDir() {
+		req.Throw(404, E("NotFoundError: "+info.Name()+" is a directory"))
+		return
+	}
+	req.ServeContent(info.Name(), info.ModTime(), f)
+}
+
+// throwFileError responds with the status of the error without the path
+// of the file which is not sent to the client
+func (req *Request) throwFileError(err error) {
+	name := "file"
+	if pe, ok := err.(*os.PathError); ok {
+		name = filepath.Base(pe.Path)
+	}
+
+	switch {
+	case os.IsNotExist(err):
+		req.Throw(404, E("NotFoundError: "+name+" does not exist"))
+	case os.IsPermission(err):
+		req.Throw(403, E("ForbiddenError: "+name+" cannot be read"))
+	default:
+		req.Throw(500, E("FileError: "+name+" cannot be sent"))
+	}
+}
+
+// Open opens a file of the FileStore for reading, the name is cleaned so
+// that it cannot be outside of the FileStore
+func (fs FileStore) Open(file string) (*os.File, error) {
+	return os.Open(filepath.Join(fs.fullPath, filepath.FromSlash(path.Clean("/"+file))))
+}
@@ -0,0 +1,100 @@
+package rubik
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func fileRequest(headers map[string]string) (*Request, *httptest.ResponseRecorder) {
+	rr := httptest.NewRecorder()
+	raw := httptest.NewRequest("GET", "/", nil)
+	for k, v := range headers {
+		raw.Header.Set(k, v)
+	}
+	return &Request{Raw: raw, Writer: RResponseWriter{ResponseWriter: rr}}, rr
+}
+
+func TestSendStoreFile(t *testing.T) {
+	store, err := Storage.Access("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, rr := fileRequest(nil)
+	req.SendStoreFile(store, "testFile")
+	etag := rr.Header().Get("ETag")
+	if rr.Code != 200 || rr.Body.String() != "test" || etag == "" ||
+		rr.Header().Get("Last-Modified") == "" ||
+		!strings.HasPrefix(rr.Header().Get(Content.Header), Content.Text) {
+		t.Fatal("SendStoreFile() did not send the file:", rr.Code, rr.Header(), rr.Body.String())
+	}
+
+	req, rr = fileRequest(map[string]string{"Range": "bytes=1-2"})
+	req.SendStoreFile(store, "testFile")
+	if rr.Code != 206 || rr.Body.String() != "es" {
+		t.Error("SendStoreFile() did not send the range:", rr.Code, rr.Body.String())
+	}
+
+	req, rr = fileRequest(map[string]string{"If-None-Match": etag})
+	req.SendStoreFile(store, "testFile")
+	if rr.Code != 304 || rr.Body.Len() != 0 {
+		t.Error("SendStoreFile() did not respond with 304:", rr.Code)
+	}
+
+	req, rr = fileRequest(nil)
+	req.SendStoreFile(store, "../../go.mod")
+	if rr.Code != 404 || strings.Contains(rr.Body.String(), "storage") {
+		t.Error("SendStoreFile() sent a file outside of the store:", rr.Code, rr.Body.String())
+	}
+}
+
+func TestSendFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rubik-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "page.html")
+	ioutil.WriteFile(file, []byte("<p>rubik</p>"), 0644)
+
+	req, rr := fileRequest(nil)
+	req.SendFile(file)
+	if rr.Code != 200 || !strings.HasPrefix(rr.Header().Get(Content.Header), Content.HTML) ||
+		rr.Body.String() != "<p>rubik</p>" {
+		t.Error("SendFile() did not send the file:", rr.Code, rr.Header(), rr.Body.String())
+	}
+
+	req, rr = fileRequest(nil)
+	req.SendFile(dir)
+	if rr.Code != 404 {
+		t.Error("SendFile() sent a directory:", rr.Code)
+	}
+
+	req, rr = fileRequest(nil)
+	req.SendFile(filepath.Join(dir, "missing.txt"))
+	if rr.Code != 404 || strings.Contains(rr.Body.String(), dir) {
+		t.Error("SendFile() did not respond with 404:", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAttachment(t *testing.T) {
+	req, rr := fileRequest(nil)
+	req.Attachment("report 1.json", ioutil.NopCloser(strings.NewReader(`{"ok":true}`)))
+	if rr.Code != 200 || rr.Body.String() != `{"ok":true}` ||
+		rr.Header().Get("Content-Disposition") != `attachment; filename="report 1.json"` ||
+		rr.Header().Get(Content.Header) != Content.JSON {
+		t.Error("Attachment() did not send the reader:", rr.Code, rr.Header(), rr.Body.String())
+	}
+
+	req, rr = fileRequest(map[string]string{"Range": "bytes=0-1"})
+	req.Attachment("data.bin", strings.NewReader("rubik"))
+	if rr.Code != 206 || rr.Body.String() != "ru" ||
+		rr.Header().Get("Content-Disposition") != `attachment; filename=data.bin` {
+		t.Error("Attachment() did not send the range:", rr.Code, rr.Header(), rr.Body.String())
+	}
+}
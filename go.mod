@@ -8,4 +8,8 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/printzero/tint v0.0.3
 	github.com/rubikorg/blocks v0.0.0-20210522181751-899798383030
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
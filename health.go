@@ -0,0 +1,158 @@
+package rubik
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HealthChecker can be implemented by a Block to report its health on
+// the /rubik/health endpoint. Health must return an error when the block
+// cannot serve requests, for example when the database is unreachable
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// HealthTimeout is the time given to the blocks to report their health
+var HealthTimeout = time.Second * 5
+
+// HealthReport is the response of the /rubik/health endpoint. The status
+// is degraded when only optional blocks have failed to attach
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Blocks map[string]BlockHealth `json:"blocks"`
+}
+
+// BlockHealth is the health of a single block
+type BlockHealth struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+const (
+	healthOK          = "ok"
+	healthDegraded    = "degraded"
+	healthFailed      = "failed"
+	healthUnavailable = "unavailable"
+)
+
+// checkHealth asks every attached block implementing HealthChecker for
+// its health. The checks run concurrently within the HealthTimeout
+func checkHealth(ctx context.Context) HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, HealthTimeout)
+	defer cancel()
+
+	checkers := make(map[string]HealthChecker)
+	for _, blocks := range []map[string]Block{app.blocks, app.afterBlocks} {
+		for name, b := range blocks {
+			if hc, ok := b.(HealthChecker); ok {
+				checkers[name] = hc
+			}
+		}
+	}
+
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, hc HealthChecker) {
+			defer wg.Done()
+			done := make(chan error, 1)
+			go func() { done <- hc.Health(ctx) }()
+
+			select {
+			case results[i] = <-done:
+			case <-ctx.Done():
+				results[i] = ctx.Err()
+			}
+		}(i, checkers[name])
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: healthOK, Blocks: make(map[string]BlockHealth)}
+	for name, err := range app.failed {
+		report.Status = healthDegraded
+		report.Blocks[name] = BlockHealth{Status: healthFailed, Error: err.Error()}
+	}
+
+	for i, name := range names {
+		if results[i] != nil {
+			report.Status = healthUnavailable
+			report.Blocks[name] = BlockHealth{Status: healthUnavailable, Error: results[i].Error()}
+			continue
+		}
+		report.Blocks[name] = BlockHealth{Status: healthOK}
+	}
+	return report
+}
+
+// healthController responds with the HealthReport of the blocks, the
+// status is 503 if any attached block is unhealthy
+func healthController(req *Request) {
+	report := checkHealth(req.Ctx)
+	status := http.StatusOK
+	if report.Status == healthUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		req.Throw(500, err)
+		return
+	}
+	writeResponse(&req.Writer, status, Content.JSON, b)
+}
+
+var builtinsBooted = false
+
+// bootBuiltins adds the internal routes of rubik under /rubik
+func bootBuiltins() {
+	if builtinsBooted {
+		return
+	}
+	builtinsBooted = true
+
+	router := Create("/rubik")
+	router.Add(Route{
+		Path:        "/health",
+		Method:      "GET",
+		Description: "Reports the health of the attached blocks",
+		Controller:  healthController,
+	})
+
+	if openAPIOpts != nil {
+		docs := Route{
+			Method:      "GET",
+			Description: "Serves the OpenAPI document of the server",
+			Controller:  openAPIController,
+		}
+
+		if strings.HasPrefix(openAPIOpts.Path, "/rubik/") {
+			docs.Path = strings.TrimPrefix(openAPIOpts.Path, "/rubik")
+			router.Add(docs)
+		} else {
+			// a path outside /rubik is part of the route tree like any route
+			docs.Path = openAPIOpts.Path
+			UseRoute(docs)
+		}
+	}
+	if docsEnabled() {
+		router.Add(Route{
+			Path:        strings.TrimPrefix(docsOpts.Path, "/rubik"),
+			Method:      "GET",
+			Description: "Serves the API documentation page",
+			Controller:  docsController,
+		})
+	}
+	Use(router)
+}
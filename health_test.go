@@ -0,0 +1,87 @@
+package rubik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+type healthBlock struct {
+	err error
+}
+
+func (hb *healthBlock) OnAttach(app *App) error { return nil }
+
+func (hb *healthBlock) Health(ctx context.Context) error { return hb.err }
+
+func TestHealthEndpoint(t *testing.T) {
+	app.blocks["healthy"] = &healthBlock{}
+	defer delete(app.blocks, "healthy")
+
+	rr := httptest.NewRecorder()
+	app.ServeHTTP(rr, httptest.NewRequest("GET", "/rubik/health", nil))
+	if rr.Code != 200 {
+		t.Fatal("/rubik/health returned", rr.Code, rr.Body.String())
+	}
+
+	app.blocks["db"] = &healthBlock{err: errors.New("connection refused")}
+	defer delete(app.blocks, "db")
+
+	rr = httptest.NewRecorder()
+	app.ServeHTTP(rr, httptest.NewRequest("GET", "/rubik/health", nil))
+	if rr.Code != 503 {
+		t.Error("/rubik/health did not return 503 for unhealthy block:", rr.Code)
+	}
+
+	var report HealthReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Status != healthUnavailable || report.Blocks["db"].Error != "connection refused" ||
+		report.Blocks["healthy"].Status != healthOK {
+		t.Error("/rubik/health returned wrong report:", report)
+	}
+}
+
+func TestLookupBlock(t *testing.T) {
+	hb := &healthBlock{}
+	app.blocks["lookup"] = hb
+	defer delete(app.blocks, "lookup")
+
+	var found *healthBlock
+	if err := LookupBlock("Lookup", &found); err != nil || found != hb {
+		t.Error("LookupBlock() did not assign the block:", err)
+	}
+
+	var checker HealthChecker
+	if err := LookupBlock("lookup", &checker); err != nil || checker == nil {
+		t.Error("LookupBlock() did not assign the block to interface:", err)
+	}
+
+	var wrong *reloadBlock
+	if err := LookupBlock("lookup", &wrong); err == nil {
+		t.Error("LookupBlock() did not return error for wrong type")
+	}
+
+	if err := LookupBlock("missing", &found); err == nil {
+		t.Error("LookupBlock() did not return error for missing block")
+	}
+
+	if err := LookupBlock("lookup", found); err == nil {
+		t.Error("LookupBlock() did not return error for non pointer target")
+	}
+}
+
+func TestBootInjections(t *testing.T) {
+	defer func() { blockInjections = nil }()
+
+	var found *healthBlock
+	InjectBlock("missing", &found)
+	err := bootInjections()
+	if be, ok := err.(BootError); !ok || be.Block != "missing" {
+		t.Error("bootInjections() did not return BootError:", err)
+	}
+}
@@ -0,0 +1,221 @@
+package rubik
+
+import (
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// HookPhase is the point inside the lifecycle of a request at which
+// a PhaseHook is executed
+type HookPhase int
+
+// Phase holds all the HookPhase values supported by rubik. The phases
+// are executed in the order in which they are declared:
+//
+// [ OnRequest --- (routing) --- BeforeGuard --- Guards() --- (injection)
+// --- AfterInjection --- []Middlewares() --- Controller() --- BeforeResponse
+// --- AfterResponse ]
+//
+// OnRequest runs for every request that reaches the server even if no route
+// matches it. BeforeResponse runs right before the status and headers are
+// written to the wire, so hooks of this phase must only modify the headers.
+// AfterResponse runs synchronously after the response is written, use
+// AfterRequest for fire-and-forget hooks.
+var Phase = struct {
+	OnRequest      HookPhase
+	BeforeGuard    HookPhase
+	AfterInjection HookPhase
+	BeforeResponse HookPhase
+	AfterResponse  HookPhase
+}{1, 2, 3, 4, 5}
+
+// PhaseHook is a synchronous request hook. It has access to the Request
+// of the current phase and can short-circuit the request by writing a
+// response, exactly like a Guard would.
+type PhaseHook func(*Request)
+
+var phaseHooks = make(map[HookPhase][]PhaseHook)
+
+// UseHook registers the hook h to be executed at the given phase of every
+// request. Hooks of the same phase are executed in the order in which they
+// are registered and the first hook to write a response stops the request
+// from going any further.
+//
+// 		rubik.UseHook(rubik.Phase.BeforeGuard, func(req *rubik.Request) {
+// 			if req.Raw.Header.Get("X-Api-Key") == "" {
+// 				req.Throw(401, rubik.E("missing api key"))
+// 			}
+// 		})
+func UseHook(phase HookPhase, h PhaseHook) {
+	phaseHooks[phase] = append(phaseHooks[phase], h)
+}
+
+// dispatchPhase runs all the hooks registered for the given phase in order
+// and returns true if one of them has written a response
+func dispatchPhase(phase HookPhase, req *Request) bool {
+	for _, h := range phaseHooks[phase] {
+		h(req)
+		if req.Writer.written {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeHTTP makes rubik an http.Handler. It runs the Phase.OnRequest hooks
+// before the request is handed over to the router. The hooks can replace
+// Request.Raw, for example to add values to it's context, and the router
+// will receive the replaced request.
+func (r *rubik) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if len(phaseHooks[Phase.OnRequest]) > 0 {
+		rubikReq := Request{
+			app:    r,
+			Raw:    req,
+			Writer: RResponseWriter{ResponseWriter: w},
+			Ctx:    req.Context(),
+		}
+		if dispatchPhase(Phase.OnRequest, &rubikReq) {
+			return
+		}
+		req = rubikReq.Raw
+	}
+
+	r.mux.ServeHTTP(w, req)
+}
+
+// DispatchPolicy decides what happens to the after hooks of a request when
+// the queue of the after hook pool is full
+type DispatchPolicy int
+
+// Dispatch holds the DispatchPolicy values supported by the after hook pool.
+// Block makes the request goroutine wait until the queue has space and Drop
+// discards the hooks of the request and counts it in HookStats.Dropped
+var Dispatch = struct {
+	Block DispatchPolicy
+	Drop  DispatchPolicy
+}{1, 2}
+
+// HookStats is a snapshot of the counters of the after hook pool
+type HookStats struct {
+	Queued     uint64
+	Dispatched uint64
+	Dropped    uint64
+}
+
+// hookPool delivers the after hooks of every request through a fixed
+// number of workers instead of spawning a goroutine per request
+type hookPool struct {
+	// counters are kept first for 64-bit alignment of atomic operations
+	queued     uint64
+	dispatched uint64
+	dropped    uint64
+	workers    int
+	queueSize  int
+	policy     DispatchPolicy
+	queue      chan *HookContext
+	hooks      []RequestHook
+	once       sync.Once
+	// mu is held by enqueueAfterHooks while sending so that stop never
+	// closes the queue under a sender
+	mu      sync.RWMutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+var afterHookPool = &hookPool{
+	workers:   runtime.NumCPU(),
+	queueSize: 1024,
+	policy:    Dispatch.Block,
+}
+
+// SetAfterHookPool configures the number of workers executing the AfterRequest
+// hooks, the size of the queue in front of them and the policy applied when
+// the queue is full. It must be called before Run, values less than 1 keep
+// the defaults of runtime.NumCPU() workers and a queue of 1024 requests.
+func SetAfterHookPool(workers, queueSize int, policy DispatchPolicy) {
+	if workers > 0 {
+		afterHookPool.workers = workers
+	}
+	if queueSize > 0 {
+		afterHookPool.queueSize = queueSize
+	}
+	if policy == Dispatch.Block || policy == Dispatch.Drop {
+		afterHookPool.policy = policy
+	}
+}
+
+// AfterHookStats returns the counters of the after hook pool, this can be
+// exposed by your metrics endpoint to know if hooks are being dropped
+func AfterHookStats() HookStats {
+	return HookStats{
+		Queued:     atomic.LoadUint64(&afterHookPool.queued),
+		Dispatched: atomic.LoadUint64(&afterHookPool.dispatched),
+		Dropped:    atomic.LoadUint64(&afterHookPool.dropped),
+	}
+}
+
+// start boots the workers of the pool only once, the workers execute
+// their own copy of the hooks so that they never read afterHooks
+func (p *hookPool) start(hooks []RequestHook) {
+	p.once.Do(func() {
+		p.hooks = append([]RequestHook(nil), hooks...)
+		p.queue = make(chan *HookContext, p.queueSize)
+		p.wg.Add(p.workers)
+		for i := 0; i < p.workers; i++ {
+			go func() {
+				defer p.wg.Done()
+				for rc := range p.queue {
+					dispatchHooks(p.hooks, rc)
+					atomic.AddUint64(&p.dispatched, 1)
+				}
+			}()
+		}
+	})
+}
+
+// stop closes the queue of a started pool and waits for the workers to
+// deliver the queued contexts, the contexts enqueued after it are dropped
+func (p *hookPool) stop() {
+	p.once.Do(func() {})
+	p.mu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		if p.queue != nil {
+			close(p.queue)
+		}
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// enqueueAfterHooks hands over the HookContext to the after hook pool
+// respecting the DispatchPolicy of the pool
+func enqueueAfterHooks(rc *HookContext) {
+	if len(afterHooks) == 0 {
+		return
+	}
+
+	p := afterHookPool
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.stopped {
+		atomic.AddUint64(&p.dropped, 1)
+		return
+	}
+
+	p.start(afterHooks)
+	if p.policy == Dispatch.Drop {
+		select {
+		case p.queue <- rc:
+			atomic.AddUint64(&p.queued, 1)
+		default:
+			atomic.AddUint64(&p.dropped, 1)
+		}
+		return
+	}
+
+	p.queue <- rc
+	atomic.AddUint64(&p.queued, 1)
+}
@@ -0,0 +1,122 @@
+package rubik
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUseHook(t *testing.T) {
+	defer delete(phaseHooks, Phase.BeforeGuard)
+
+	var order []int
+	UseHook(Phase.BeforeGuard, func(req *Request) { order = append(order, 1) })
+	UseHook(Phase.BeforeGuard, func(req *Request) { order = append(order, 2) })
+
+	req := &Request{Writer: RResponseWriter{ResponseWriter: httptest.NewRecorder()}}
+	if dispatchPhase(Phase.BeforeGuard, req) {
+		t.Error("dispatchPhase() short-circuited when no hook has written a response")
+	}
+
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Errorf("hooks are not executed in the order of registration: %v", order)
+	}
+}
+
+func TestUseHookShortCircuit(t *testing.T) {
+	defer delete(phaseHooks, Phase.OnRequest)
+
+	called := false
+	UseHook(Phase.OnRequest, func(req *Request) {
+		req.Throw(401, E("unauthorized"), Type.Text)
+	})
+	UseHook(Phase.OnRequest, func(req *Request) { called = true })
+
+	rr := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+	app.ServeHTTP(rr, r)
+
+	if rr.Code != 401 {
+		t.Errorf("OnRequest hook did not short-circuit the request, status: %d", rr.Code)
+	}
+
+	if called {
+		t.Error("hook registered after a responding hook was executed")
+	}
+}
+
+func TestBeforeResponseHook(t *testing.T) {
+	defer delete(phaseHooks, Phase.BeforeResponse)
+
+	UseHook(Phase.BeforeResponse, func(req *Request) {
+		req.Writer.Header().Set("X-Rubik", "hooked")
+	})
+
+	rr := httptest.NewRecorder()
+	req := &Request{Writer: RResponseWriter{ResponseWriter: rr}}
+	req.Writer.beforeHeader = func() { dispatchPhase(Phase.BeforeResponse, req) }
+	req.Respond("ok", Type.Text)
+
+	if rr.Header().Get("X-Rubik") != "hooked" {
+		t.Error("BeforeResponse hook could not set a response header")
+	}
+}
+
+func TestEnqueueAfterHooksDrop(t *testing.T) {
+	pool := afterHookPool
+	hooks := afterHooks
+	release := make(chan struct{})
+	afterHooks = []RequestHook{func(rc *HookContext) { <-release }}
+	afterHookPool = &hookPool{workers: 1, queueSize: 1, policy: Dispatch.Drop}
+	defer func() {
+		// the worker is stopped before the hooks it copied are restored
+		afterHookPool.stop()
+		afterHookPool = pool
+		afterHooks = hooks
+	}()
+
+	// one context is taken by the worker, one fills the queue and
+	// the rest must be dropped
+	for i := 0; i < 5; i++ {
+		enqueueAfterHooks(&HookContext{})
+	}
+	close(release)
+	afterHookPool.stop()
+
+	stats := AfterHookStats()
+	if stats.Dropped == 0 || stats.Queued+stats.Dropped != 5 ||
+		stats.Dispatched != stats.Queued {
+		t.Errorf("after hook pool did not drop contexts when queue was full: %+v", stats)
+	}
+}
+
+func TestEnqueueAfterHooksStopped(t *testing.T) {
+	pool := afterHookPool
+	hooks := afterHooks
+	afterHooks = []RequestHook{func(rc *HookContext) {}}
+	afterHookPool = &hookPool{workers: 1, queueSize: 1, policy: Dispatch.Block}
+	defer func() {
+		afterHookPool = pool
+		afterHooks = hooks
+	}()
+
+	// stopped before the first request the pool is never started and
+	// a blocking enqueue must not wait on the nil queue
+	afterHookPool.stop()
+	done := make(chan struct{})
+	go func() {
+		enqueueAfterHooks(&HookContext{})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("enqueueAfterHooks() blocked after the pool was stopped")
+	}
+
+	if stats := AfterHookStats(); stats.Dropped != 1 || stats.Queued != 0 {
+		t.Errorf("after hook pool did not drop the context enqueued after stop: %+v", stats)
+	}
+}
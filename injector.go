@@ -1,6 +1,8 @@
 package rubik
 
 import (
+	"bytes"
+	"encoding/gob"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -20,7 +22,8 @@ import (
 func inject(req *http.Request,
 	pm httprouter.Params, en interface{}, v Validation) (interface{}, error) {
 	// lets check what type of request it is
-	ctype := req.Header.Get(Content.Header)
+	// parameters like the boundary of multipart are not part of the type
+	ctype := strings.TrimSpace(strings.Split(req.Header.Get(Content.Header), ";")[0])
 	var body = make(map[string]interface{})
 	var params = make(map[string]string)
 	// check if any params in the route
@@ -35,8 +38,17 @@ func inject(req *http.Request,
 	if err != nil {
 		return nil, err
 	}
+	// the body is restored so that the form can be parsed from it
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
 
+	// gob bodies are decoded into the entity directly
+	isGob := false
 	switch ctype {
+	case Content.Gob:
+		if err := gob.NewDecoder(bytes.NewReader(b)).Decode(en); err != nil {
+			return nil, err
+		}
+		isGob = true
 	case Content.JSON:
 		err = json.Unmarshal(b, &body)
 		if err != nil {
@@ -49,7 +61,6 @@ func inject(req *http.Request,
 		for k, v := range encs {
 			body[k] = v[0]
 		}
-	// TODO: need to evalueate multipart form data
 	case Content.Multipart:
 		err := req.ParseMultipartForm(32 << 20)
 		if err != nil {
@@ -58,6 +69,13 @@ func inject(req *http.Request,
 		break
 	}
 
+	// form fields are read from the query when the body is not a form
+	if req.Form == nil {
+		if err := req.ParseForm(); err != nil {
+			return nil, err
+		}
+	}
+
 	values := reflect.ValueOf(en)
 	fields := values.Elem().Type()
 	num := values.Elem().NumField()
@@ -69,35 +87,8 @@ func inject(req *http.Request,
 			continue
 		}
 
-		tag := field.Tag.Get(rubikTag)
 		value := values.Elem().Field(i)
-		transport := "query"
-		transportKey := unCapitalize(field.Name)
-		isRequired := false
-
-		if strings.Contains(tag, "!") {
-			isRequired = true
-			tag = strings.ReplaceAll(tag, "!", "")
-		}
-		// get information from the tag
-		if tag != "" {
-			if strings.Contains(tag, "|") {
-				reqTag := strings.Split(tag, "|")
-				if reqTag[0] != "" {
-					transportKey = reqTag[0]
-				}
-				if reqTag[1] != "" {
-					transport = reqTag[1]
-				}
-
-			} else {
-				if isOneOf(tag, "query", "body", "form", "param") {
-					transport = tag
-				} else {
-					transportKey = tag
-				}
-			}
-		}
+		transportKey, transport, isRequired := parseEntityTag(field)
 
 		msg := "Data: %s is required but not found inside %s."
 		requiredError := errors.New(fmt.Sprintf(msg, transportKey, transport))
@@ -110,6 +101,14 @@ func inject(req *http.Request,
 			}
 			break
 		case "body":
+			if isGob {
+				val = value.Interface()
+				if value.IsZero() && isRequired {
+					return nil, requiredError
+				}
+				break
+			}
+
 			val = body[transportKey]
 			if (val == nil || val == "") && isRequired {
 				return nil, requiredError
@@ -142,12 +141,66 @@ func inject(req *http.Request,
 			}
 		}
 
+		if isGob && transport == "body" {
+			continue
+		}
 		injectValueByType(val, value, field.Type.Kind())
 	}
 
 	return en, nil
 }
 
+// parseEntityTag returns the key of the field inside the request, the
+// transport it is read from (query, body, form or param) and if it is
+// required, as defined by the rubik tag of the field:
+//
+// 		Name string `rubik:"name|body!"`
+func parseEntityTag(field reflect.StructField) (string, string, bool) {
+	tag := field.Tag.Get(rubikTag)
+	transport := "query"
+	transportKey := unCapitalize(field.Name)
+	isRequired := false
+
+	if strings.Contains(tag, "!") {
+		isRequired = true
+		tag = strings.ReplaceAll(tag, "!", "")
+	}
+	// get information from the tag
+	if tag != "" {
+		if strings.Contains(tag, "|") {
+			reqTag := strings.Split(tag, "|")
+			if reqTag[0] != "" {
+				transportKey = reqTag[0]
+			}
+			if reqTag[1] != "" {
+				transport = reqTag[1]
+			}
+
+		} else {
+			if isOneOf(tag, "query", "body", "form", "param") {
+				transport = tag
+			} else {
+				transportKey = tag
+			}
+		}
+	}
+	return transportKey, transport, isRequired
+}
+
+// scalarString returns the string form of a value read from the query, the
+// form or a JSON body in which numbers are float64 and booleans are bool
+func scalarString(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
 func injectValueByType(val interface{}, elem reflect.Value, kind reflect.Kind) {
 	switch kind {
 	case reflect.String:
@@ -157,7 +210,7 @@ func injectValueByType(val interface{}, elem reflect.Value, kind reflect.Kind) {
 		}
 		break
 	case reflect.Int:
-		value, _ := val.(string)
+		value := scalarString(val)
 		if value == "" {
 			return
 		}
@@ -168,7 +221,7 @@ func injectValueByType(val interface{}, elem reflect.Value, kind reflect.Kind) {
 		}
 		break
 	case reflect.Float32:
-		value, _ := val.(string)
+		value := scalarString(val)
 		if value == "" {
 			return
 		}
@@ -178,7 +231,7 @@ func injectValueByType(val interface{}, elem reflect.Value, kind reflect.Kind) {
 		}
 		break
 	case reflect.Float64:
-		value, _ := val.(string)
+		value := scalarString(val)
 		if value == "" {
 			return
 		}
@@ -188,7 +241,7 @@ func injectValueByType(val interface{}, elem reflect.Value, kind reflect.Kind) {
 		}
 		break
 	case reflect.Bool:
-		value, _ := val.(string)
+		value := scalarString(val)
 		if value == "" {
 			return
 		}
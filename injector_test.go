@@ -0,0 +1,79 @@
+package rubik
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type injectFormEn struct {
+	Entity
+	Name string `rubik:"name|form!"`
+	Page string `rubik:"page|form"`
+}
+
+func TestInjectFormFields(t *testing.T) {
+	req := httptest.NewRequest("POST", "/?page=2", strings.NewReader("name=rubik"))
+	req.Header.Set(Content.Header, Content.URLEncoded+"; charset=utf-8")
+	en, err := inject(req, nil, &injectFormEn{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := en.(*injectFormEn); f.Name != "rubik" || f.Page != "2" {
+		t.Error("inject() did not read the form fields:", f)
+	}
+
+	req = httptest.NewRequest("POST", "/?name=rubik", strings.NewReader("{}"))
+	req.Header.Set(Content.Header, Content.JSON)
+	en, err = inject(req, nil, &injectFormEn{}, nil)
+	if err != nil || en.(*injectFormEn).Name != "rubik" {
+		t.Error("inject() did not read the form fields from the query:", en, err)
+	}
+}
+
+func TestInjectContentTypeParams(t *testing.T) {
+	type bodyEn struct {
+		Entity
+		Name string `rubik:"name|body!"`
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"rubik"}`))
+	req.Header.Set(Content.Header, Content.JSON+"; charset=utf-8")
+	en, err := inject(req, nil, &bodyEn{}, nil)
+	if err != nil || en.(*bodyEn).Name != "rubik" {
+		t.Error("inject() did not read the JSON body with a charset:", en, err)
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("name", "rubik")
+	mw.Close()
+	req = httptest.NewRequest("POST", "/", &buf)
+	req.Header.Set(Content.Header, mw.FormDataContentType())
+	en, err = inject(req, nil, &injectFormEn{}, nil)
+	if err != nil || en.(*injectFormEn).Name != "rubik" {
+		t.Error("inject() did not read the multipart form with a boundary:", en, err)
+	}
+}
+
+func TestInjectRestoresBody(t *testing.T) {
+	type bodyEn struct {
+		Entity
+		Name string `rubik:"name|body"`
+	}
+
+	body := `{"name":"rubik"}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set(Content.Header, Content.JSON)
+	if _, err := inject(req, nil, &bodyEn{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil || string(b) != body {
+		t.Errorf("inject() did not restore the body: %q %v", b, err)
+	}
+}
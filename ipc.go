@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"reflect"
 	"time"
-
-	"github.com/rubikorg/rubik/pkg"
 )
 
 // IpcMessage is the structure using which Rubik services
@@ -34,13 +32,14 @@ func (ipc ipcModem) Send(msgType string, service string, message interface{}) {
 
 		_, err := txClient.Post(ipcRxEn)
 		if err != nil {
-			pkg.ErrorMsg(err.Error())
+			app.logger.Error("Message could not be published", "message", msgType,
+				"error", err)
 		}
 
 		fmt.Printf("Message: %s, published\n", msgType)
 		return
 	}
-	pkg.ErrorMsg(fmt.Sprintf("%s is not present in this workspace", service))
+	app.logger.Error("Service is not present in this workspace", "service", service)
 }
 
 // OnMessage registers a IpcMessage handler for the given
@@ -0,0 +1,110 @@
+package rubik
+
+import (
+	"sync"
+
+	"github.com/rubikorg/rubik/pkg"
+)
+
+// logChannel drains the rubik.Log channels into the LogStream
+// configured for this service inside rubik.toml
+type logChannel struct {
+	stream *pkg.LogStream
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+var logCh *logChannel
+var logOnce sync.Once
+
+// getLoggingConfig returns the logging config of the current service
+// from rubik.toml, it returns the default stdout config if the project
+// is not a rubik workspace or the service is not declared in it
+func getLoggingConfig(service string) pkg.LoggingConfig {
+	ws, err := pkg.GetRubikConfig()
+	if err != nil {
+		return pkg.LoggingConfig{}
+	}
+
+	for _, p := range ws.App {
+		if p.Name == service {
+			return p.Logging
+		}
+	}
+
+	return pkg.LoggingConfig{}
+}
+
+// bootLogChannel starts draining the rubik.Log channels into the
+// streams of the LoggingConfig of the current service. It is started
+// only once even if boot is called multiple times
+func bootLogChannel() error {
+	var err error
+	logOnce.Do(func() {
+		var stream *pkg.LogStream
+		stream, err = pkg.NewLogStream(getLoggingConfig(app.currentService),
+			app.currentService)
+		if err != nil {
+			return
+		}
+
+		logCh = &logChannel{
+			stream: stream,
+			stop:   make(chan struct{}),
+			done:   make(chan struct{}),
+		}
+		go logCh.run()
+	})
+	return err
+}
+
+func (lc *logChannel) run() {
+	defer close(lc.done)
+	for {
+		select {
+		case msg := <-Log.E:
+			lc.stream.Write(pkg.ErrorLevel, msg)
+		case msg := <-Log.W:
+			lc.stream.Write(pkg.WarnLevel, msg)
+		case msg := <-Log.I:
+			lc.stream.Write(pkg.InfoLevel, msg)
+		case msg := <-Log.D:
+			lc.stream.Write(pkg.DebugLevel, msg)
+		case <-lc.stop:
+			lc.drain()
+			lc.stream.Close()
+			return
+		}
+	}
+}
+
+// drain writes the messages which are still buffered inside the
+// rubik.Log channels
+func (lc *logChannel) drain() {
+	for {
+		select {
+		case msg := <-Log.E:
+			lc.stream.Write(pkg.ErrorLevel, msg)
+		case msg := <-Log.W:
+			lc.stream.Write(pkg.WarnLevel, msg)
+		case msg := <-Log.I:
+			lc.stream.Write(pkg.InfoLevel, msg)
+		case msg := <-Log.D:
+			lc.stream.Write(pkg.DebugLevel, msg)
+		default:
+			return
+		}
+	}
+}
+
+// flushLogChannel writes all pending log messages and closes the log
+// files, it is called when the server shuts down
+func flushLogChannel() {
+	if logCh == nil {
+		return
+	}
+
+	close(logCh.stop)
+	<-logCh.done
+	logCh = nil
+}
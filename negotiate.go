@@ -0,0 +1,270 @@
+package rubik
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encoder converts the data given to Respond into the bytes of the media
+// type it is registered for. An Encoder returns ErrCannotEncode when it
+// cannot represent the data so that the next acceptable media type is
+// tried
+type Encoder func(req *Request, data interface{}) ([]byte, error)
+
+// ErrCannotEncode is returned by an Encoder which cannot encode the data
+var ErrCannotEncode = errors.New("EncoderError: cannot encode the data in this media type")
+
+type registeredEncoder struct {
+	mediaType string
+	encode    Encoder
+}
+
+// encoders are ordered by the preference of the server which decides
+// between the media types accepted with the same quality
+var encoders = []registeredEncoder{
+	{Content.JSON, encodeJSON},
+	{Content.XML, encodeXML},
+	{Content.Text, encodeText},
+	{Content.HTML, encodeHTML},
+	{Content.MsgPack, encodeMsgPack},
+	{Content.Gob, encodeGob},
+	{Content.Bytes, encodeBytes},
+}
+
+// RegisterEncoder adds the encoder of a media type used by Respond when
+// the response type is Type.Auto, it replaces the encoder if the media
+// type is already registered. Register your encoders before calling Run
+//
+// 		rubik.RegisterEncoder("application/yaml", func(req *rubik.Request,
+// 			data interface{}) ([]byte, error) {
+// 			return yaml.Marshal(data)
+// 		})
+func RegisterEncoder(mediaType string, enc Encoder) {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for i, e := range encoders {
+		if e.mediaType == mediaType {
+			encoders[i].encode = enc
+			return
+		}
+	}
+	encoders = append(encoders, registeredEncoder{mediaType, enc})
+}
+
+func findEncoder(mediaType string) Encoder {
+	for _, e := range encoders {
+		if e.mediaType == mediaType {
+			return e.encode
+		}
+	}
+	return nil
+}
+
+func encodeJSON(req *Request, data interface{}) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+func encodeXML(req *Request, data interface{}) ([]byte, error) {
+	b, err := xml.Marshal(data)
+	var ute *xml.UnsupportedTypeError
+	if errors.As(err, &ute) {
+		return nil, ErrCannotEncode
+	}
+	return b, err
+}
+
+func encodeText(req *Request, data interface{}) ([]byte, error) {
+	switch v := data.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case error:
+		return []byte(v.Error()), nil
+	case fmt.Stringer:
+		return []byte(v.String()), nil
+	}
+	return nil, ErrCannotEncode
+}
+
+// encodeHTML renders the template bound to the request with the data
+func encodeHTML(req *Request, data interface{}) ([]byte, error) {
+	if len(req.templates) == 0 {
+		return nil, ErrCannotEncode
+	}
+
+	bresp := RenderContent(Type.HTML, data, req.templates...)
+	if bresp.Error != nil {
+		return nil, bresp.Error
+	}
+	return bresp.Data.([]byte), nil
+}
+
+func encodeMsgPack(req *Request, data interface{}) ([]byte, error) {
+	return msgpack.Marshal(data)
+}
+
+// BindTemplate binds the templates which render the data of Respond as
+// HTML when the client accepts text/html and the response type is
+// Type.Auto. Bind them in a middleware to use them for a whole router:
+//
+// 		func usersPage(req *rubik.Request) {
+// 			req.BindTemplate("users.html")
+// 			req.Respond(users, rubik.Type.Auto)
+// 		}
+func (req *Request) BindTemplate(paths ...string) {
+	req.templates = paths
+}
+
+// mediaRange is a media range of the Accept header with its quality
+type mediaRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// parseAccept returns the media ranges of the Accept header, an empty
+// header accepts everything
+func parseAccept(header string) []mediaRange {
+	if strings.TrimSpace(header) == "" {
+		return []mediaRange{{"*", "*", 1}}
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		typ := strings.ToLower(strings.TrimSpace(params[0]))
+		slash := strings.Index(typ, "/")
+		if slash < 0 {
+			if typ != "*" {
+				continue
+			}
+			typ, slash = "*/*", 1
+		}
+
+		mr := mediaRange{typ: typ[:slash], subtype: typ[slash+1:], q: 1}
+		for _, p := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) == 2 && strings.ToLower(kv[0]) == "q" {
+				if q, err := strconv.ParseFloat(kv[1], 64); err == nil && q >= 0 && q <= 1 {
+					mr.q = q
+				}
+			}
+		}
+		ranges = append(ranges, mr)
+	}
+	return ranges
+}
+
+// quality returns the quality of the media type given by the most
+// specific media range which matches it, 0 when no range matches
+func quality(ranges []mediaRange, mediaType string) float64 {
+	slash := strings.Index(mediaType, "/")
+	if slash < 0 {
+		return 0
+	}
+	typ, subtype := mediaType[:slash], mediaType[slash+1:]
+
+	best, specificity := 0.0, -1
+	for _, mr := range ranges {
+		s := -1
+		switch {
+		case mr.typ == typ && mr.subtype == subtype:
+			s = 2
+		case mr.typ == typ && mr.subtype == "*":
+			s = 1
+		case mr.typ == "*" && mr.subtype == "*":
+			s = 0
+		}
+
+		if s > specificity {
+			best, specificity = mr.q, s
+		}
+	}
+	return best
+}
+
+// acceptable returns the registered media types accepted by the client
+// ordered by their quality and then by the preference of the server
+func acceptable(header string) []string {
+	ranges := parseAccept(header)
+	var types []string
+	var qs []float64
+	for _, e := range encoders {
+		q := quality(ranges, e.mediaType)
+		if q <= 0 {
+			continue
+		}
+
+		i := len(types)
+		for i > 0 && qs[i-1] < q {
+			i--
+		}
+		types = append(types[:i], append([]string{e.mediaType}, types[i:]...)...)
+		qs = append(qs[:i], append([]float64{q}, qs[i:]...)...)
+	}
+	return types
+}
+
+// addVary adds the header to the Vary header of the response unless it
+// is already present
+func addVary(h http.Header, header string) {
+	for _, v := range h.Values("Vary") {
+		for _, f := range strings.Split(v, ",") {
+			f = strings.TrimSpace(f)
+			if f == "*" || strings.EqualFold(f, header) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", header)
+}
+
+// negotiate writes the data in the first media type accepted by the
+// client which can encode it or responds with 406 Not Acceptable
+func (req *Request) negotiate(status int, data interface{}) {
+	addVary(req.Writer.Header(), "Accept")
+
+	accept := ""
+	if req.Raw != nil {
+		accept = strings.Join(req.Raw.Header.Values("Accept"), ",")
+	}
+
+	types := acceptable(accept)
+	for _, mediaType := range types {
+		b, err := findEncoder(mediaType)(req, data)
+		if errors.Is(err, ErrCannotEncode) {
+			continue
+		} else if err != nil {
+			req.Throw(500, err)
+			return
+		}
+
+		writeResponse(&req.Writer, status, mediaType, b)
+		return
+	}
+
+	var available []string
+	for _, e := range encoders {
+		available = append(available, e.mediaType)
+	}
+	req.Throw(http.StatusNotAcceptable, fmt.Errorf("NotAcceptableError: cannot respond "+
+		"with any of the accepted media types %q, available: %s", accept,
+		strings.Join(available, ", ")), Type.Text)
+}
+
+// respondWith encodes the data with the encoder of the media type
+func (req *Request) respondWith(status int, mediaType string, data interface{}) {
+	b, err := findEncoder(mediaType)(req, data)
+	if err != nil {
+		req.Throw(500, err)
+		return
+	}
+	writeResponse(&req.Writer, status, mediaType, b)
+}
@@ -0,0 +1,92 @@
+package rubik
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type negotiateUser struct {
+	Name string `json:"name" xml:"name" msgpack:"name"`
+}
+
+func negotiateRequest(accept string) (*Request, *httptest.ResponseRecorder) {
+	rr := httptest.NewRecorder()
+	raw := httptest.NewRequest("GET", "/", nil)
+	if accept != "" {
+		raw.Header.Set("Accept", accept)
+	}
+	return &Request{Raw: raw, Writer: RResponseWriter{ResponseWriter: rr}}, rr
+}
+
+func TestAcceptable(t *testing.T) {
+	cases := map[string][]string{
+		"":                                     {Content.JSON, Content.XML, Content.Text, Content.HTML, Content.MsgPack, Content.Gob, Content.Bytes},
+		"text/*;q=0.5, application/xml":        {Content.XML, Content.Text, Content.HTML},
+		"text/html,*/*;q=0.8":                  {Content.HTML, Content.JSON, Content.XML, Content.Text, Content.MsgPack, Content.Gob, Content.Bytes},
+		"*/*, application/json;q=0":            {Content.XML, Content.Text, Content.HTML, Content.MsgPack, Content.Gob, Content.Bytes},
+		"application/msgpack;q=0.9, image/png": {Content.MsgPack},
+	}
+
+	for accept, want := range cases {
+		if got := acceptable(accept); !reflect.DeepEqual(got, want) {
+			t.Errorf("acceptable(%q) = %v, want %v", accept, got, want)
+		}
+	}
+}
+
+func TestRespondAuto(t *testing.T) {
+	user := negotiateUser{Name: "rubik"}
+
+	req, rr := negotiateRequest("application/xml")
+	req.Respond(user, Type.Auto)
+	if rr.Header().Get(Content.Header) != Content.XML ||
+		rr.Body.String() != "<negotiateUser><name>rubik</name></negotiateUser>" {
+		t.Error("Respond() did not write XML:", rr.Header(), rr.Body.String())
+	}
+	if rr.Header().Get("Vary") != "Accept" {
+		t.Error("Respond() did not set Vary:", rr.Header())
+	}
+
+	req, rr = negotiateRequest("application/msgpack")
+	req.Writer.Header().Set("Vary", "Origin, accept")
+	req.Respond(user, Type.Auto)
+	var decoded negotiateUser
+	if err := msgpack.Unmarshal(rr.Body.Bytes(), &decoded); err != nil || decoded != user {
+		t.Error("Respond() did not write msgpack:", err, decoded)
+	}
+	if len(rr.Header().Values("Vary")) != 1 {
+		t.Error("Respond() added Vary twice:", rr.Header().Values("Vary"))
+	}
+
+	// text and HTML cannot encode a struct without a bound template
+	req, rr = negotiateRequest("text/plain, text/html")
+	req.Respond(user, Type.Auto)
+	if rr.Code != 406 || !strings.Contains(rr.Body.String(), "NotAcceptableError") ||
+		rr.Header().Get("Vary") != "Accept" {
+		t.Error("Respond() did not respond with 406:", rr.Code, rr.Body.String())
+	}
+
+	req, rr = negotiateRequest("text/html;q=0.9, text/plain")
+	req.Respond("hello", Type.Auto)
+	if rr.Header().Get(Content.Header) != Content.Text || rr.Body.String() != "hello" {
+		t.Error("Respond() did not write text:", rr.Header(), rr.Body.String())
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	defer func(e []registeredEncoder) { encoders = e }(append([]registeredEncoder{}, encoders...))
+
+	RegisterEncoder("Application/CSV", func(req *Request, data interface{}) ([]byte, error) {
+		return []byte("name\n" + data.(negotiateUser).Name + "\n"), nil
+	})
+
+	req, rr := negotiateRequest("application/csv")
+	req.Respond(negotiateUser{Name: "rubik"}, Type.Auto)
+	if rr.Header().Get(Content.Header) != "application/csv" || rr.Body.String() != "name\nrubik\n" {
+		t.Error("Respond() did not use the registered encoder:", rr.Header(), rr.Body.String())
+	}
+}
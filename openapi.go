@@ -0,0 +1,397 @@
+package rubik
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification generated
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPIOptions configures the OpenAPI document of your server.
+//
+// Title and Version describe your API and default to the name of the
+// service and 1.0.0. When ExportedOnly is true only the routes with
+// Export set to true are documented. Path is the route at which the
+// document is served by ServeOpenAPI, /rubik/openapi.json by default.
+type OpenAPIOptions struct {
+	Title        string
+	Version      string
+	Description  string
+	Servers      []string
+	ExportedOnly bool
+	Path         string
+}
+
+// defaultOpenAPIPath is the route at which the OpenAPI document is served
+const defaultOpenAPIPath = "/rubik/openapi.json"
+
+var openAPIOpts *OpenAPIOptions
+
+// ServeOpenAPI serves the OpenAPI document of your routes at the Path of
+// the options
+//
+// 		rubik.ServeOpenAPI(rubik.OpenAPIOptions{
+// 			Title:        "Users API",
+// 			ExportedOnly: true,
+// 		})
+func ServeOpenAPI(opts OpenAPIOptions) {
+	if opts.Path == "" {
+		opts.Path = defaultOpenAPIPath
+	}
+	openAPIOpts = &opts
+}
+
+// OpenAPI returns the OpenAPI 3.1 document of the routes inside the tree
+func OpenAPI(tree RouteTree, opts OpenAPIOptions) map[string]interface{} {
+	return openAPIDocument(describeAPI(tree), opts)
+}
+
+// paramSyntax matches the :param and *catchall segments of httprouter
+var paramSyntax = regexp.MustCompile(`[:*]([^/]+)`)
+
+// openAPIPath converts the httprouter path into an OpenAPI path template
+// and returns the names of the path parameters
+func openAPIPath(p string) (string, []string) {
+	var names []string
+	for _, m := range paramSyntax.FindAllStringSubmatch(p, -1) {
+		names = append(names, m[1])
+	}
+	return paramSyntax.ReplaceAllString(p, "{$1}"), names
+}
+
+// schemaBuilder builds the JSON schemas of Go types, named structs are
+// added to the components of the document and referenced
+type schemaBuilder struct {
+	components map[string]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (sb *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Float32:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": sb.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": sb.schema(t.Elem()),
+		}
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		if t == fileType {
+			return map[string]interface{}{"type": "string", "format": "binary"}
+		}
+		if t.Name() == "" {
+			return sb.structSchema(t)
+		}
+
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := sb.components[t.Name()]; !ok {
+			// added before building so that recursive types end with the $ref
+			sb.components[t.Name()] = map[string]interface{}{}
+			sb.components[t.Name()] = sb.structSchema(t)
+		}
+		return ref
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema returns the object schema of the exported fields of the
+// struct named by their json tags
+func (sb *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Name
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		} else if tag[0] != "" {
+			name = tag[0]
+		}
+
+		if f.Anonymous && tag[0] == "" && f.Type.Kind() == reflect.Struct {
+			embedded := sb.structSchema(f.Type)
+			for k, v := range embedded["properties"].(map[string]interface{}) {
+				props[k] = v
+			}
+			continue
+		}
+
+		props[name] = sb.schema(f.Type)
+		if !strings.Contains(f.Tag.Get("json"), "omitempty") && f.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+
+	s := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// fieldsSchema returns the object schema of the entity fields
+func (sb *schemaBuilder) fieldsSchema(fields []FieldInfo) map[string]interface{} {
+	props := make(map[string]interface{})
+	var required []string
+	for _, f := range fields {
+		props[f.Key] = sb.schema(f.GoType)
+		if f.Required {
+			required = append(required, f.Key)
+		}
+	}
+
+	s := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// isUploadField reports whether the field is a File or a slice of File
+func isUploadField(f FieldInfo) bool {
+	if f.GoType != nil && f.GoType.Kind() == reflect.Slice {
+		f.GoType = f.GoType.Elem()
+	}
+	return isFileField(f)
+}
+
+// openAPIDocument builds the OpenAPI document of the APIDescription
+func openAPIDocument(desc APIDescription, opts OpenAPIOptions) map[string]interface{} {
+	if opts.Title == "" {
+		opts.Title = desc.Service
+	}
+	if opts.Title == "" {
+		opts.Title = "Rubik API"
+	}
+	if opts.Version == "" {
+		opts.Version = "1.0.0"
+	}
+
+	info := map[string]interface{}{"title": opts.Title, "version": opts.Version}
+	if opts.Description != "" {
+		info["description"] = opts.Description
+	}
+
+	sb := &schemaBuilder{components: make(map[string]interface{})}
+	paths := make(map[string]interface{})
+	for _, r := range desc.Routes {
+		if opts.ExportedOnly && !r.Export {
+			continue
+		}
+
+		p, pathParams := openAPIPath(r.Path)
+		item, ok := paths[p].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[p] = item
+		}
+		// the description has one route per method but a hand made one
+		// can still have "GET|POST" methods
+		for _, m := range routeMethods(r.Method) {
+			r.Method = m
+			item[strings.ToLower(m)] = sb.operation(r, pathParams)
+		}
+	}
+
+	doc := map[string]interface{}{
+		"openapi": OpenAPIVersion,
+		"info":    info,
+		"paths":   paths,
+	}
+
+	if len(opts.Servers) > 0 {
+		var servers []map[string]string
+		for _, s := range opts.Servers {
+			servers = append(servers, map[string]string{"url": s})
+		}
+		doc["servers"] = servers
+	}
+
+	if len(sb.components) > 0 {
+		doc["components"] = map[string]interface{}{"schemas": sb.components}
+	}
+	return doc
+}
+
+// operationID returns the id of the operation made from the method and
+// the path: GET /users/:id is getUsersId
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == ':' || r == '*' || r == '-' || r == '_' || r == '.'
+	}) {
+		id += capitalize(part)
+	}
+	return id
+}
+
+// operation returns the OpenAPI operation of the route
+func (sb *schemaBuilder) operation(r APIRoute, pathParams []string) map[string]interface{} {
+	op := map[string]interface{}{
+		"operationId": operationID(r.Method, r.Path),
+	}
+	if r.Description != "" {
+		op["summary"] = r.Description
+	}
+	if r.Router != "" {
+		op["tags"] = []string{r.Router}
+	}
+
+	var params []map[string]interface{}
+	declared := make(map[string]bool)
+	var bodyFields, formFields []FieldInfo
+	for _, f := range r.Fields {
+		switch f.In {
+		case "body":
+			bodyFields = append(bodyFields, f)
+		case "form":
+			formFields = append(formFields, f)
+		case "param":
+			declared[f.Key] = true
+			params = append(params, map[string]interface{}{
+				"name": f.Key, "in": "path", "required": true, "schema": sb.schema(f.GoType),
+			})
+		default:
+			params = append(params, map[string]interface{}{
+				"name": f.Key, "in": "query", "required": f.Required,
+				"schema": sb.schema(f.GoType),
+			})
+		}
+	}
+
+	// path parameters which are not part of the entity are strings
+	for _, name := range pathParams {
+		if !declared[name] {
+			params = append(params, map[string]interface{}{
+				"name": name, "in": "path", "required": true,
+				"schema": map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	content := make(map[string]interface{})
+	if len(bodyFields) > 0 {
+		content[Content.JSON] = map[string]interface{}{"schema": sb.fieldsSchema(bodyFields)}
+	}
+	if len(formFields) > 0 {
+		// files can only be uploaded as multipart
+		formType := Content.URLEncoded
+		for _, f := range formFields {
+			if isUploadField(f) {
+				formType = Content.Multipart
+			}
+		}
+		content[formType] = map[string]interface{}{"schema": sb.fieldsSchema(formFields)}
+	}
+	if len(content) > 0 {
+		required := false
+		for _, f := range append(bodyFields, formFields...) {
+			required = required || f.Required
+		}
+		op["requestBody"] = map[string]interface{}{"required": required, "content": content}
+	}
+
+	op["responses"] = sb.responses(r)
+	return op
+}
+
+// responses returns the OpenAPI responses of the route using the declared
+// responses and the response types of the route
+func (sb *schemaBuilder) responses(r APIRoute) map[string]interface{} {
+	statuses := make(map[int]bool)
+	for status := range r.Responses {
+		statuses[status] = true
+	}
+	for status := range r.ResponseTypes {
+		statuses[status] = true
+	}
+	if len(statuses) == 0 {
+		statuses[http.StatusOK] = true
+	}
+
+	codes := make([]int, 0, len(statuses))
+	for status := range statuses {
+		codes = append(codes, status)
+	}
+	sort.Ints(codes)
+
+	responses := make(map[string]interface{})
+	for _, status := range codes {
+		desc := r.Responses[status]
+		if desc == "" {
+			desc = http.StatusText(status)
+		}
+
+		resp := map[string]interface{}{"description": desc}
+		if t, ok := r.ResponseTypes[status]; ok {
+			ctype := Content.JSON
+			if t.Kind() == reflect.String {
+				ctype = Content.Text
+			}
+			resp["content"] = map[string]interface{}{
+				ctype: map[string]interface{}{"schema": sb.schema(t)},
+			}
+		}
+		responses[strconv.Itoa(status)] = resp
+	}
+	return responses
+}
+
+var openAPIDoc []byte
+var openAPIErr error
+var openAPIOnce sync.Once
+
+// openAPIController serves the OpenAPI document which is built once as
+// the routes do not change after boot
+func openAPIController(req *Request) {
+	openAPIOnce.Do(func() {
+		openAPIDoc, openAPIErr = json.Marshal(OpenAPI(req.app.routeTree, *openAPIOpts))
+	})
+
+	if openAPIErr != nil {
+		req.Throw(500, openAPIErr)
+		return
+	}
+	writeResponse(&req.Writer, 200, Content.JSON, openAPIDoc)
+}
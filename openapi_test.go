@@ -0,0 +1,171 @@
+package rubik
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type userResponse struct {
+	ID      int             `json:"id"`
+	Name    string          `json:"name"`
+	Friends []*userResponse `json:"friends,omitempty"`
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	p, names := openAPIPath("/users/:id/files/*filepath")
+	if p != "/users/{id}/files/{filepath}" || len(names) != 2 || names[1] != "filepath" {
+		t.Error("openAPIPath() returned", p, names)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	tree := testRouteTree()
+	tree.Routes[0].Export = true
+	tree.Routes[0].Responses = map[int]string{200: "The updated user", 404: "User not found"}
+	tree.Routes[0].ResponseTypes = map[int]interface{}{200: userResponse{}}
+
+	doc := OpenAPI(tree, OpenAPIOptions{Title: "Users"})
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Parameters  []struct {
+				Name     string `json:"name"`
+				In       string `json:"in"`
+				Required bool   `json:"required"`
+			} `json:"parameters"`
+			RequestBody struct {
+				Content map[string]interface{} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Description string                 `json:"description"`
+				Content     map[string]interface{} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.OpenAPI != OpenAPIVersion || len(parsed.Paths) != 2 {
+		t.Fatal("OpenAPI() returned wrong document:", string(b))
+	}
+
+	post := parsed.Paths["/users/{id}"]["post"]
+	if post.OperationID != "postUsersId" || len(post.Parameters) != 2 ||
+		post.Parameters[0].In != "path" || post.Parameters[1].In != "query" {
+		t.Error("OpenAPI() returned wrong parameters:", post.Parameters)
+	}
+
+	if _, ok := post.RequestBody.Content[Content.JSON]; !ok {
+		t.Error("OpenAPI() did not add the body fields to request body")
+	}
+
+	if post.Responses["404"].Description != "User not found" ||
+		post.Responses["200"].Content[Content.JSON] == nil {
+		t.Error("OpenAPI() returned wrong responses:", post.Responses)
+	}
+
+	if parsed.Components.Schemas["userResponse"] == nil {
+		t.Error("OpenAPI() did not add the response type to components")
+	}
+
+	get := parsed.Paths["/users/{id}"]["get"]
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "id" ||
+		get.Responses["200"].Description != "OK" {
+		t.Error("OpenAPI() did not add the path parameter of the route:", get)
+	}
+
+	exported := OpenAPI(tree, OpenAPIOptions{ExportedOnly: true})
+	if paths := exported["paths"].(map[string]interface{}); len(paths) != 1 {
+		t.Error("OpenAPI() did not filter the exported routes:", paths)
+	}
+}
+
+func TestOpenAPIMultiMethod(t *testing.T) {
+	tree := RouteTree{Routes: []RouteInfo{
+		{FullPath: "/items/:id", Method: "GET|POST", Entity: describeEn{}},
+	}}
+
+	for _, doc := range []map[string]interface{}{
+		OpenAPI(tree, OpenAPIOptions{}),
+		openAPIDocument(APIDescription{Routes: []APIRoute{{Method: "GET|POST",
+			Path: "/items/:id"}}}, OpenAPIOptions{}),
+	} {
+		item := doc["paths"].(map[string]interface{})["/items/{id}"].(map[string]interface{})
+		get, _ := item["get"].(map[string]interface{})
+		post, _ := item["post"].(map[string]interface{})
+		if len(item) != 2 || get == nil || post == nil {
+			t.Fatal("OpenAPI() did not split the methods:", item)
+		}
+
+		if get["operationId"] != "getItemsId" || post["operationId"] != "postItemsId" {
+			t.Error("OpenAPI() returned wrong operation ids:", get["operationId"],
+				post["operationId"])
+		}
+	}
+}
+
+func TestOpenAPIFileUpload(t *testing.T) {
+	type uploadEn struct {
+		Entity
+		Avatar File   `rubik:"avatar|form!"`
+		Photos []File `rubik:"photos|form"`
+		Note   string `rubik:"note|form"`
+	}
+
+	doc := openAPIDocument(APIDescription{Routes: []APIRoute{{Method: "POST",
+		Path: "/upload", Fields: entityFields(uploadEn{})}}}, OpenAPIOptions{})
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed struct {
+		Paths map[string]map[string]struct {
+			RequestBody struct {
+				Content map[string]struct {
+					Schema struct {
+						Properties map[string]struct {
+							Type   string `json:"type"`
+							Format string `json:"format"`
+							Items  struct {
+								Type   string `json:"type"`
+								Format string `json:"format"`
+							} `json:"items"`
+						} `json:"properties"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+		} `json:"paths"`
+		Components map[string]interface{} `json:"components"`
+	}
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	content := parsed.Paths["/upload"]["post"].RequestBody.Content
+	form, ok := content[Content.Multipart]
+	if !ok || len(content) != 1 {
+		t.Fatal("OpenAPI() did not use multipart for the file fields:", string(b))
+	}
+
+	props := form.Schema.Properties
+	if props["avatar"].Type != "string" || props["avatar"].Format != "binary" ||
+		props["photos"].Type != "array" || props["photos"].Items.Format != "binary" ||
+		props["note"].Type != "string" {
+		t.Error("OpenAPI() did not describe the files as binary strings:", string(b))
+	}
+
+	if parsed.Components != nil {
+		t.Error("OpenAPI() added a component for File:", parsed.Components)
+	}
+}
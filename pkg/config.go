@@ -3,6 +3,8 @@ package pkg
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
@@ -17,11 +19,17 @@ import (
 // stream. err_path is used to stream log files with Error Level
 // Format supports formatting with the following placeholders
 // 		- () = Date format, $level and $message
+//
+// Files of the file stream are rotated when they grow beyond
+// max_size megabytes or are older than max_age hours. A value of
+// 0 disables the respective rotation.
 type LoggingConfig struct {
 	Stream    string `toml:"stream"`
 	Path      string `toml:"path"`
 	ErrorPath string `toml:"err_path"`
 	Format    string `toml:"format"`
+	MaxSize   int    `toml:"max_size"`
+	MaxAge    int    `toml:"max_age"`
 }
 
 // Project defines the struct representation of rubik.toml
@@ -81,7 +89,7 @@ func GetWorkspaceConfig(path string) (*WorkspaceConfig, error) {
 	var config WorkspaceConfig
 	_, err := toml.DecodeFile(path, &config)
 	if err != nil {
-		WarnMsg("rubik.toml was found but could not parse it. Error: " + err.Error())
+		DefaultLogger.Warn("rubik.toml was found but could not parse it", "error", err)
 		return nil, errors.New("Cannot parse rubik.toml, please verify if it is valid TOML file")
 	}
 	return &config, nil
@@ -104,10 +112,71 @@ func GetErrorHTMLPath() string {
 	return filepath.Join(cacheFolder, "error.html")
 }
 
-// OverrideValues writes over the source map with env map
+// OverrideValues writes over the source map with env map, only the top
+// level keys are replaced. Use MergeValues for merging nested tables
 func OverrideValues(source, env map[string]interface{}) map[string]interface{} {
 	for k, v := range env {
 		source[k] = v
 	}
 	return source
 }
+
+// MergeValues recursively merges the env map over the source map and
+// returns the source map. The rules of merging are:
+//
+// 		- tables (maps) are merged key by key at every level
+// 		- values and arrays of env replace the values of source
+// 		- arrays are appended to the array of source when the key in
+// 		  env ends with a "+", for example: "hosts+" = ["c.com"]
+func MergeValues(source, env map[string]interface{}) map[string]interface{} {
+	if source == nil {
+		source = make(map[string]interface{})
+	}
+
+	for k, v := range env {
+		if strings.HasSuffix(k, "+") {
+			key := strings.TrimSuffix(k, "+")
+			source[key] = appendValues(source[key], v)
+			continue
+		}
+
+		envTable, isEnvTable := v.(map[string]interface{})
+		sourceTable, isSourceTable := source[k].(map[string]interface{})
+		if isEnvTable && isSourceTable {
+			source[k] = MergeValues(sourceTable, envTable)
+			continue
+		}
+
+		source[k] = v
+	}
+	return source
+}
+
+// appendValues appends the env array to the source array, arrays of
+// tables are kept as arrays of tables if both of them are one
+func appendValues(source, env interface{}) interface{} {
+	if source == nil {
+		return env
+	}
+
+	sourceTables, ok := source.([]map[string]interface{})
+	envTables, envOk := env.([]map[string]interface{})
+	if ok && envOk {
+		return append(append([]map[string]interface{}{}, sourceTables...), envTables...)
+	}
+
+	return append(toSlice(source), toSlice(env)...)
+}
+
+func toSlice(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []interface{}{v}
+	}
+
+	out := make([]interface{}, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		out = append(out, rv.Index(i).Interface())
+	}
+	return out
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/BurntSushi/toml"
 )
 
 func TestGetTemplateFolderPath(t *testing.T) {
@@ -43,6 +45,83 @@ func TestOverrideValues(t *testing.T) {
 	}
 }
 
+func decodeTOML(t *testing.T, content string) map[string]interface{} {
+	var m map[string]interface{}
+	if _, err := toml.Decode(content, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestMergeValues(t *testing.T) {
+	source := decodeTOML(t, `
+port = "8000"
+[database]
+host = "localhost"
+user = "rubik"
+[database.pool]
+min = 1
+max = 10
+`)
+	env := decodeTOML(t, `
+[database]
+host = "db.prod"
+[database.pool]
+max = 50
+`)
+
+	result := MergeValues(source, env)
+	db := result["database"].(map[string]interface{})
+	pool := db["pool"].(map[string]interface{})
+
+	if result["port"] != "8000" {
+		t.Error("MergeValues() removed a top level key which was not overridden")
+	}
+
+	if db["host"] != "db.prod" || db["user"] != "rubik" {
+		t.Error("MergeValues() did not merge nested table. database:", db)
+	}
+
+	if pool["max"] != int64(50) || pool["min"] != int64(1) {
+		t.Error("MergeValues() did not merge multi-level nested table. pool:", pool)
+	}
+}
+
+func TestMergeValuesArrays(t *testing.T) {
+	source := decodeTOML(t, `
+hosts = ["a.com", "b.com"]
+origins = ["a.com"]
+[[servers]]
+name = "alpha"
+`)
+	env := decodeTOML(t, `
+hosts = ["c.com"]
+"origins+" = ["c.com"]
+[["servers+"]]
+name = "beta"
+`)
+
+	result := MergeValues(source, env)
+	hosts := result["hosts"].([]interface{})
+	if len(hosts) != 1 || hosts[0] != "c.com" {
+		t.Error("MergeValues() did not replace the array. hosts:", hosts)
+	}
+
+	origins := result["origins"].([]interface{})
+	if len(origins) != 2 || origins[1] != "c.com" {
+		t.Error("MergeValues() did not append the array with + key. origins:", origins)
+	}
+
+	servers := result["servers"].([]map[string]interface{})
+	if len(servers) != 2 || servers[1]["name"] != "beta" {
+		t.Error("MergeValues() did not append the array of tables. servers:", servers)
+	}
+
+	if _, ok := result["origins+"]; ok {
+		t.Error("MergeValues() kept the + key inside the merged map")
+	}
+}
+
 func TestGetStaticPath(t *testing.T) {
 	sp := filepath.Join(".", "static")
 	p := GetStaticFolderPath()
@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigExtensions are the config file extensions supported by rubik in
+// the order in which they are looked up
+var ConfigExtensions = []string{".toml", ".json", ".yaml", ".yml", ".env"}
+
+// DecodeConfig decodes the content of a config file into a map depending
+// upon the extension of the file. The values are normalized to the types
+// produced by the TOML decoder so that every format behaves the same
+func DecodeConfig(ext string, b []byte) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	switch strings.ToLower(ext) {
+	case ".toml":
+		if _, err := toml.Decode(string(b), &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		if err := dec.Decode(&m); err != nil {
+			return nil, err
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+	case ".env":
+		return decodeDotenv(b)
+	default:
+		return nil, fmt.Errorf("ConfigDecodeError: %s config files are not supported", ext)
+	}
+
+	return normalize(m).(map[string]interface{}), nil
+}
+
+// decodeDotenv decodes KEY=VALUE lines, double underscore inside the
+// key separates the tables: DATABASE__HOST=localhost is decoded as
+// the host key of the database table
+func decodeDotenv(b []byte) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimPrefix(text, "export ")
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("ConfigDecodeError: line %d is not in the form KEY=VALUE",
+				line)
+		}
+
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		// values are parsed like the env overrides, quoted values are
+		// TOML strings and single quotes keep the value as it is
+		SetValue(m, strings.Split(key, "__"), ParseValue(strings.TrimSpace(parts[1])))
+	}
+
+	return m, scanner.Err()
+}
+
+// normalize converts the values decoded by JSON and YAML decoders into the
+// types of the TOML decoder: int64, float64 and []map[string]interface{}
+// for arrays of tables
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, x := range val {
+			val[k] = normalize(x)
+		}
+		return val
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, x := range val {
+			m[fmt.Sprint(k)] = normalize(x)
+		}
+		return m
+	case []interface{}:
+		tables := make([]map[string]interface{}, 0, len(val))
+		for i, x := range val {
+			val[i] = normalize(x)
+			if t, ok := val[i].(map[string]interface{}); ok {
+				tables = append(tables, t)
+			}
+		}
+		if len(val) > 0 && len(tables) == len(val) {
+			return tables
+		}
+		return val
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case int:
+		return int64(val)
+	case float32:
+		return float64(val)
+	}
+	return v
+}
@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestDecodeConfigJSON(t *testing.T) {
+	m, err := DecodeConfig(".json", []byte(`{"port": 8000, "ratio": 0.5,
+		"database": {"host": "localhost"}, "servers": [{"name": "a"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m["port"] != int64(8000) || m["ratio"] != 0.5 {
+		t.Error("DecodeConfig() did not normalize JSON numbers. config:", m)
+	}
+
+	if _, ok := m["servers"].([]map[string]interface{}); !ok {
+		t.Error("DecodeConfig() did not normalize JSON array of tables. config:", m)
+	}
+}
+
+func TestDecodeConfigYAML(t *testing.T) {
+	m, err := DecodeConfig(".yaml", []byte("port: 8000\ndatabase:\n  host: localhost\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, ok := m["database"].(map[string]interface{})
+	if m["port"] != int64(8000) || !ok || db["host"] != "localhost" {
+		t.Error("DecodeConfig() did not decode YAML properly. config:", m)
+	}
+}
+
+func TestDecodeConfigDotenv(t *testing.T) {
+	m, err := DecodeConfig(".env", []byte("# comment\nPORT=8000\n"+
+		"DATABASE__HOST=\"db.prod\"\nexport SECRET='a=b'\nNAME=rubik app\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, ok := m["database"].(map[string]interface{})
+	if m["port"] != int64(8000) || !ok || db["host"] != "db.prod" ||
+		m["secret"] != "a=b" || m["name"] != "rubik app" {
+		t.Error("DecodeConfig() did not decode dotenv properly. config:", m)
+	}
+
+	_, err = DecodeConfig(".env", []byte("NOVALUE\n"))
+	if err == nil {
+		t.Error("DecodeConfig() did not return error for malformed dotenv line")
+	}
+}
+
+func TestDecodeConfigUnsupported(t *testing.T) {
+	_, err := DecodeConfig(".ini", []byte(""))
+	if err == nil {
+		t.Error("DecodeConfig() did not return error for unsupported extension")
+	}
+}
@@ -1,38 +1,379 @@
 package pkg
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/printzero/tint"
 )
 
 var t = tint.Init()
 
-// Logger is the go to logging struct for anything related to logs
+// Level is the severity of a log message
+type Level int
+
+const (
+	// LevelDebug is used for messages useful only while developing
+	LevelDebug Level = iota
+	// LevelInfo is used for informational messages
+	LevelInfo
+	// LevelWarn is used for messages which needs attention
+	LevelWarn
+	// LevelError is used for errors
+	LevelError
+)
+
+const (
+	// FormatText writes human readable lines, colourised when writing to a TTY
+	FormatText = "text"
+	// FormatJSON writes one JSON object per line
+	FormatJSON = "json"
+	// FormatLogfmt writes key=value pairs per line
+	FormatLogfmt = "logfmt"
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// String returns the lowercase name of the level
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel returns the Level for the given name: debug, info, warn or error
+func ParseLevel(name string) (Level, error) {
+	for l, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return l, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("LoggerError: unknown log level %s", name)
+}
+
+// loggerCore is shared between a Logger and all of it's children
+// so that the output, format and level can be changed for all of them
+type loggerCore struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format string
+	level  Level
+	color  bool
+}
+
+// Logger is the go to logging struct for anything related to logs.
+// It writes leveled messages with key/value fields as text, JSON or
+// logfmt. Child loggers created using Named and With share the output,
+// format and level of their parent.
 type Logger struct {
+	// CanLog set to false disables the logger
 	CanLog bool
+	core   *loggerCore
+	name   string
+	fields []interface{}
+}
+
+// DefaultLogger is the logger used by rubik. It's format and minimum
+// level can be set with RUBIK_LOG_FORMAT and RUBIK_LOG_LEVEL env
+// variables, by default it writes text with level info
+var DefaultLogger = newDefaultLogger()
+
+func newDefaultLogger() *Logger {
+	level, err := ParseLevel(os.Getenv("RUBIK_LOG_LEVEL"))
+	if err != nil {
+		level = LevelInfo
+	}
+	return NewLogger(os.Stdout, os.Getenv("RUBIK_LOG_FORMAT"), level)
+}
+
+// NewLogger creates a Logger writing to out in the given format. The
+// text format is colourised only if out is a terminal
+func NewLogger(out io.Writer, format string, level Level) *Logger {
+	format = strings.ToLower(format)
+	if format != FormatJSON && format != FormatLogfmt {
+		format = FormatText
+	}
+
+	return &Logger{
+		CanLog: true,
+		core: &loggerCore{
+			out:    out,
+			format: format,
+			level:  level,
+			color:  isTerminal(out),
+		},
+	}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func (l *Logger) getCore() *loggerCore {
+	if l.core == nil {
+		return DefaultLogger.core
+	}
+	return l.core
+}
+
+// SetLevel sets the minimum level of messages written by this logger
+// and all the loggers sharing it's output
+func (l *Logger) SetLevel(level Level) {
+	c := l.getCore()
+	c.mu.Lock()
+	c.level = level
+	c.mu.Unlock()
+}
+
+// SetOutput changes the writer of this logger and all the loggers
+// sharing it's output
+func (l *Logger) SetOutput(out io.Writer) {
+	c := l.getCore()
+	c.mu.Lock()
+	c.out = out
+	c.color = isTerminal(out)
+	c.mu.Unlock()
+}
+
+// Enabled reports if messages of the given level will be written
+func (l *Logger) Enabled(level Level) bool {
+	c := l.getCore()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return l.CanLog && level >= c.level
+}
+
+// Named returns a child logger which adds the logger=name field to
+// every message, used for per-package loggers
+func (l *Logger) Named(name string) *Logger {
+	child := l.With()
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	child.name = name
+	return child
+}
+
+// With returns a child logger which adds the given key/value pairs
+// to every message
+func (l *Logger) With(kv ...interface{}) *Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(kv))
+	fields = append(fields, l.fields...)
+	fields = append(fields, kv...)
+	return &Logger{
+		CanLog: l.CanLog,
+		core:   l.getCore(),
+		name:   l.name,
+		fields: fields,
+	}
+}
+
+// Debug writes msg with the key/value pairs at debug level
+func (l *Logger) Debug(msg string, kv ...interface{}) {
+	l.log(LevelDebug, msg, kv)
+}
+
+// Info writes msg with the key/value pairs at info level
+func (l *Logger) Info(msg string, kv ...interface{}) {
+	l.log(LevelInfo, msg, kv)
+}
+
+// Warn writes msg with the key/value pairs at warn level
+func (l *Logger) Warn(msg string, kv ...interface{}) {
+	l.log(LevelWarn, msg, kv)
+}
+
+// Error writes msg with the key/value pairs at error level
+func (l *Logger) Error(msg string, kv ...interface{}) {
+	l.log(LevelError, msg, kv)
+}
+
+func (l *Logger) log(level Level, msg string, kv []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	fields := append(append([]interface{}{}, l.fields...), kv...)
+	if len(fields)%2 != 0 {
+		fields = append(fields, "(MISSING)")
+	}
+
+	c := l.getCore()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var line string
+	switch c.format {
+	case FormatJSON:
+		line = l.jsonLine(level, msg, fields)
+	case FormatLogfmt:
+		line = l.logfmtLine(level, msg, fields)
+	default:
+		line = l.textLine(level, msg, fields, c.color)
+	}
+	io.WriteString(c.out, line+"\n")
+}
+
+func (l *Logger) jsonLine(level Level, msg string, fields []interface{}) string {
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	writeJSON(&buf, time.Now().Format(time.RFC3339))
+	buf.WriteString(`,"level":`)
+	writeJSON(&buf, level.String())
+	if l.name != "" {
+		buf.WriteString(`,"logger":`)
+		writeJSON(&buf, l.name)
+	}
+	buf.WriteString(`,"msg":`)
+	writeJSON(&buf, msg)
+	for i := 0; i < len(fields); i += 2 {
+		buf.WriteByte(',')
+		writeJSON(&buf, fmt.Sprint(fields[i]))
+		buf.WriteByte(':')
+		writeJSON(&buf, fieldValue(fields[i+1]))
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+func (l *Logger) logfmtLine(level Level, msg string, fields []interface{}) string {
+	var b strings.Builder
+	b.WriteString("time=" + time.Now().Format(time.RFC3339))
+	b.WriteString(" level=" + level.String())
+	if l.name != "" {
+		b.WriteString(" logger=" + logfmtValue(l.name))
+	}
+	b.WriteString(" msg=" + logfmtValue(msg))
+	for i := 0; i < len(fields); i += 2 {
+		b.WriteString(fmt.Sprintf(" %v=%s", fields[i],
+			logfmtValue(fmt.Sprint(fieldValue(fields[i+1])))))
+	}
+	return b.String()
+}
+
+func (l *Logger) textLine(level Level, msg string, fields []interface{}, color bool) string {
+	var b strings.Builder
+	for i := 0; i < len(fields); i += 2 {
+		b.WriteString(fmt.Sprintf(" %v=%v", fields[i], fieldValue(fields[i+1])))
+	}
+
+	name := ""
+	if l.name != "" {
+		name = "(" + l.name + ") "
+	}
+
+	label := strings.ToUpper(level.String())
+	if !color {
+		return "[" + label + "] " + name + msg + b.String()
+	}
+
+	labelColor := tint.Cyan
+	switch level {
+	case LevelInfo:
+		labelColor = tint.Green
+	case LevelWarn:
+		labelColor = tint.Yellow
+	case LevelError:
+		labelColor = tint.Red
+	}
+	template := "@([)@(" + label + ")@(]) "
+	return t.Exp(template, tint.White.Bold(), labelColor, tint.White.Bold()) +
+		name + msg + b.String()
+}
+
+// fieldValue converts errors into their messages so that they are
+// not encoded as empty objects
+func fieldValue(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(b)
+}
+
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
 }
 
 // DebugMsg appends a debug before the log message
+//
+// Deprecated: use DefaultLogger.Debug which supports key/value fields
 func DebugMsg(message string) {
-	template := "@([)@(DEBUG)@(]) " + message
-	fmt.Println(t.Exp(template, tint.White.Bold(), tint.Cyan, tint.White.Bold()))
+	DefaultLogger.Debug(message)
 }
 
 // WarnMsg appends a warn before the log message
+//
+// Deprecated: use DefaultLogger.Warn which supports key/value fields
 func WarnMsg(message string) {
-	template := "@([)@(WARN)@(]) " + message
-	fmt.Println(t.Exp(template, tint.White.Bold(), tint.Yellow, tint.White.Bold()))
+	DefaultLogger.Warn(message)
 }
 
 // ErrorMsg appends a error before the log message
+//
+// Deprecated: use DefaultLogger.Error which supports key/value fields
 func ErrorMsg(message string) {
-	template := "@([)@(ERROR)@(]) " + message
-	fmt.Println(t.Exp(template, tint.White.Bold(), tint.Red, tint.White.Bold()))
+	DefaultLogger.Error(message)
 }
 
-// EmojiMsg writes the booting message to stdout
+// EmojiMsg writes the booting message to stdout. It is written only
+// when the DefaultLogger writes info messages, structured formats
+// receive it as an info message
 func EmojiMsg(emoji string, message string) {
+	DefaultLogger.Emoji(emoji, message)
+}
+
+// Emoji writes the booting message prefixed by the emoji. It is written
+// only when the logger writes info messages, structured formats receive
+// it as an info message
+func (l *Logger) Emoji(emoji string, message string) {
+	if !l.Enabled(LevelInfo) {
+		return
+	}
+
+	c := l.getCore()
+	c.mu.Lock()
+	format := c.format
+	c.mu.Unlock()
+	if format != FormatText {
+		l.Info(message)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	template := fmt.Sprintf("  %s  @(%s)", emoji, message)
-	fmt.Println(t.Exp(template, tint.Normal.Bold()))
+	if !c.color {
+		fmt.Fprintf(c.out, "  %s  %s\n", emoji, message)
+		return
+	}
+	fmt.Fprintln(c.out, t.Exp(template, tint.Normal.Bold()))
 }
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoggerJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, FormatJSON, LevelInfo)
+	logger.Named("cache").With("block", "redis").Error("cannot connect", "error",
+		errors.New("refused"))
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatal("Logger did not write valid JSON. Line:", buf.String())
+	}
+
+	if line["level"] != "error" || line["logger"] != "cache" || line["msg"] != "cannot connect" ||
+		line["block"] != "redis" || line["error"] != "refused" {
+		t.Error("Logger did not write all the fields. Line:", buf.String())
+	}
+}
+
+func TestLoggerLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, FormatLogfmt, LevelDebug)
+	logger.Debug("route booted", "route", "/users/:id")
+
+	line := buf.String()
+	if !strings.Contains(line, `level=debug msg="route booted" route=/users/:id`) {
+		t.Error("Logger did not write logfmt properly. Line:", line)
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, FormatText, LevelWarn)
+	child := logger.Named("child")
+	child.Info("hidden")
+	if buf.Len() != 0 {
+		t.Error("Logger wrote a message below the minimum level:", buf.String())
+	}
+
+	logger.SetLevel(LevelInfo)
+	child.Info("shown")
+	if buf.String() != "[INFO] (child) shown\n" {
+		t.Error("child Logger did not follow the level of it's parent. Line:", buf.String())
+	}
+}
+
+func TestLoggerEmoji(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, FormatText, LevelInfo)
+	logger.Emoji("⚡️", "/static")
+	if buf.String() != "  ⚡️  /static\n" {
+		t.Error("Emoji() did not write the boot message. Line:", buf.String())
+	}
+
+	buf.Reset()
+	logger = NewLogger(&buf, FormatLogfmt, LevelWarn)
+	logger.Emoji("", "/users")
+	logger.SetLevel(LevelInfo)
+	logger.Emoji("", "/users")
+	if !strings.Contains(buf.String(), `level=info msg=/users`) ||
+		strings.Count(buf.String(), "\n") != 1 {
+		t.Error("Emoji() did not follow the format and level. Line:", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	l, err := ParseLevel("WARN")
+	if err != nil || l != LevelWarn {
+		t.Error("ParseLevel() did not parse warn level")
+	}
+
+	_, err = ParseLevel("verbose")
+	if err == nil {
+		t.Error("ParseLevel() did not return an error for unknown level")
+	}
+}
@@ -0,0 +1,214 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// EnvOverrides returns the config overrides present in the environ slice
+// (in the form of os.Environ) as dotted key to raw value. Only variables
+// starting with the prefix are used, double underscore separates the
+// tables of the key. For example with RUBIK_CFG_ prefix:
+//
+// 		RUBIK_CFG_DATABASE__HOST=db.prod -> database.host = db.prod
+func EnvOverrides(prefix string, environ []string) map[string]string {
+	overrides := make(map[string]string)
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, prefix) {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(kv, prefix), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			continue
+		}
+
+		key := strings.ToLower(strings.ReplaceAll(parts[0], "__", "."))
+		overrides[key] = parts[1]
+	}
+	return overrides
+}
+
+// ParseOverride splits a key=value override into its dotted key and its
+// raw value
+func ParseOverride(kv string) (string, string, error) {
+	parts := strings.SplitN(kv, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("ConfigOverrideError: %s is not in the form key=value", kv)
+	}
+	return parts[0], parts[1], nil
+}
+
+// ParseValue parses a raw override value as a TOML value so that
+// numbers, booleans, dates and arrays keep their type. Anything that is
+// not a valid TOML value is used as a string
+func ParseValue(raw string) interface{} {
+	var holder map[string]interface{}
+	if _, err := toml.Decode("v = "+raw, &holder); err == nil {
+		return holder["v"]
+	}
+	return raw
+}
+
+// ApplyOverrides sets every dotted key of overrides inside the config
+// map creating the missing tables. Keys are matched case-insensitively
+// with the keys already present in the map. The raw values are converted
+// to the type of the field of the config struct t at their key, see
+// OverrideValue, t can be nil when the type of the config is not known
+func ApplyOverrides(config map[string]interface{}, overrides map[string]string,
+	t reflect.Type) {
+	// sorted so that a table is created before the keys inside it
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		path := strings.Split(k, ".")
+		SetValue(config, path, OverrideValue(config, t, path, overrides[k]))
+	}
+}
+
+// OverrideValue converts the raw override value of the path. The value is
+// kept as a string when the field of t at the path is a string or when
+// there is no such field and the config already has a string there,
+// otherwise it is parsed with ParseValue. So RUBIK_CFG_DB__PASS=12345
+// stays a string for a string field and becomes a number for an int field
+func OverrideValue(config map[string]interface{}, t reflect.Type, path []string,
+	raw string) interface{} {
+	if ft := fieldType(t, path); ft != nil {
+		if ft.Kind() == reflect.String || reflect.PtrTo(ft).Implements(textUnmarshaler) {
+			return raw
+		}
+		return ParseValue(raw)
+	}
+
+	if v, ok := GetValue(config, path); ok {
+		if _, isString := v.(string); isString {
+			return raw
+		}
+	}
+	return ParseValue(raw)
+}
+
+// fieldType returns the type of the field at the path inside the struct
+// t matching the keys like the TOML decoder does, nil if it is not known
+func fieldType(t reflect.Type, path []string) reflect.Type {
+	for _, segment := range path {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil {
+			return nil
+		}
+
+		switch t.Kind() {
+		case reflect.Map:
+			if t.Key().Kind() != reflect.String {
+				return nil
+			}
+			t = t.Elem()
+		case reflect.Struct:
+			var next reflect.Type
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				if f.PkgPath != "" || f.Tag.Get("toml") == "-" {
+					continue
+				}
+				if strings.EqualFold(fieldKey(f), segment) {
+					next = f.Type
+					break
+				}
+			}
+			if next == nil {
+				return nil
+			}
+			t = next
+		default:
+			return nil
+		}
+	}
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Interface {
+		return nil
+	}
+	return t
+}
+
+// SetValue sets the value at the path inside the map creating the
+// missing tables, a non-table value in the middle of the path is
+// replaced by a table
+func SetValue(m map[string]interface{}, path []string, value interface{}) {
+	current := m
+	for i, segment := range path {
+		key := matchKey(current, segment)
+		if i == len(path)-1 {
+			current[key] = value
+			return
+		}
+
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+}
+
+// GetValue returns the value at the path inside the map, keys are
+// matched case-insensitively
+func GetValue(m map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = m
+	for _, segment := range path {
+		table, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = table[matchKey(table, segment)]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// DeleteValue removes the value at the path inside the map
+func DeleteValue(m map[string]interface{}, path []string) {
+	if len(path) == 0 {
+		return
+	}
+
+	parent, ok := GetValue(m, path[:len(path)-1])
+	if !ok {
+		return
+	}
+
+	if table, ok := parent.(map[string]interface{}); ok {
+		delete(table, matchKey(table, path[len(path)-1]))
+	}
+}
+
+// matchKey returns the key of m which matches the segment ignoring case
+// or the segment itself if there is none
+func matchKey(m map[string]interface{}, segment string) string {
+	if _, ok := m[segment]; ok {
+		return segment
+	}
+
+	for k := range m {
+		if strings.EqualFold(k, segment) {
+			return k
+		}
+	}
+	return segment
+}
@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvOverrides(t *testing.T) {
+	overrides := EnvOverrides("RUBIK_CFG_", []string{
+		"RUBIK_CFG_DATABASE__HOST=db.prod",
+		"RUBIK_CFG_PORT=9000",
+		"HOME=/root",
+	})
+
+	if len(overrides) != 2 || overrides["database.host"] != "db.prod" ||
+		overrides["port"] != "9000" {
+		t.Error("EnvOverrides() did not read prefixed variables. overrides:", overrides)
+	}
+}
+
+func TestParseOverride(t *testing.T) {
+	key, value, err := ParseOverride("c=x=y")
+	if err != nil || key != "c" || value != "x=y" {
+		t.Error("ParseOverride() returned", key, value, err)
+	}
+
+	if _, _, err := ParseOverride("c"); err == nil {
+		t.Error("ParseOverride() did not return error for missing value")
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	config := map[string]interface{}{
+		"Database": map[string]interface{}{
+			"host": "localhost",
+			"user": "rubik",
+		},
+	}
+
+	ApplyOverrides(config, map[string]string{
+		"database.host": "db.prod",
+		"database.port": "5432",
+		"database.user": "1234",
+		"cache.enabled": "true",
+	}, nil)
+
+	db := config["Database"].(map[string]interface{})
+	if db["host"] != "db.prod" || db["user"] != "1234" || db["port"] != int64(5432) {
+		t.Error("ApplyOverrides() did not override nested keys. database:", db)
+	}
+
+	cache, ok := config["cache"].(map[string]interface{})
+	if !ok || cache["enabled"] != true {
+		t.Error("ApplyOverrides() did not create missing tables. config:", config)
+	}
+}
+
+type overrideConfig struct {
+	DB struct {
+		Pass  string   `toml:"pass"`
+		Port  int      `toml:"port"`
+		Hosts []string `toml:"hosts"`
+	} `toml:"db"`
+	Labels map[string]string `toml:"labels"`
+}
+
+func TestApplyOverridesFieldTypes(t *testing.T) {
+	config := make(map[string]interface{})
+	ApplyOverrides(config, map[string]string{
+		"db.pass":     "12345",
+		"db.port":     "5432",
+		"db.hosts":    `["a", "b"]`,
+		"labels.team": "true",
+		"other":       "42",
+	}, reflect.TypeOf(overrideConfig{}))
+
+	db := config["db"].(map[string]interface{})
+	if db["pass"] != "12345" || db["port"] != int64(5432) {
+		t.Error("ApplyOverrides() did not use the types of the fields. db:", db)
+	}
+
+	if hosts, ok := db["hosts"].([]interface{}); !ok || len(hosts) != 2 {
+		t.Error("ApplyOverrides() did not parse the array field. hosts:", db["hosts"])
+	}
+
+	labels := config["labels"].(map[string]interface{})
+	if labels["team"] != "true" || config["other"] != int64(42) {
+		t.Error("ApplyOverrides() did not convert map values or unknown keys. config:", config)
+	}
+}
@@ -0,0 +1,302 @@
+package pkg
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigProblem is a single problem found while validating the config
+type ConfigProblem struct {
+	Source  string
+	Key     string
+	Message string
+}
+
+// ConfigError is returned by Load when the config does not match the
+// struct it is loaded into. It lists every problem found in the config
+type ConfigError struct {
+	Problems []ConfigProblem
+}
+
+// Error implements the error interface of Go
+func (ce ConfigError) Error() string {
+	lines := []string{fmt.Sprintf("ConfigError: %d problem(s) found in config", len(ce.Problems))}
+	for _, p := range ce.Problems {
+		lines = append(lines, fmt.Sprintf("\t%s: %s %s", p.Source, p.Key, p.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FlattenKeys returns the dotted paths of every key of the map including
+// the keys of nested tables
+func FlattenKeys(m map[string]interface{}) []string {
+	var keys []string
+	for k, v := range m {
+		keys = append(keys, k)
+		if t, ok := v.(map[string]interface{}); ok {
+			for _, nk := range FlattenKeys(t) {
+				keys = append(keys, k+"."+nk)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ConfigSchema validates a decoded config against the struct it will be
+// decoded into. The struct fields are validated using the config tag:
+//
+// 		type Config struct {
+// 			Port     int    `toml:"port" config:"required,min=1,max=65535"`
+// 			LogLevel string `toml:"log_level" config:"enum=debug|info|warn"`
+// 		}
+//
+// min and max compare numbers by value and strings and arrays by length.
+// Origins maps the dotted keys to the file they were read from and, when
+// Strict is true, keys without a matching field are reported. The values
+// of the Secrets keys are redacted from the problems.
+type ConfigSchema struct {
+	Origins map[string]string
+	Strict  bool
+	Secrets []string
+}
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Validate returns all the problems of values for the target type
+func (cs ConfigSchema) Validate(target reflect.Type, values map[string]interface{}) []ConfigProblem {
+	var problems []ConfigProblem
+	cs.validateStruct(derefType(target), values, "", &problems)
+	return problems
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func (cs ConfigSchema) source(key string) string {
+	if s, ok := cs.Origins[key]; ok {
+		return s
+	}
+	return "config"
+}
+
+// display returns the value shown inside the problems of the key
+func (cs ConfigSchema) display(key string, val interface{}) interface{} {
+	for _, s := range cs.Secrets {
+		if s == key {
+			return Redacted
+		}
+	}
+	return val
+}
+
+func (cs ConfigSchema) problem(problems *[]ConfigProblem, key, msg string, args ...interface{}) {
+	*problems = append(*problems, ConfigProblem{
+		Source:  cs.source(key),
+		Key:     key,
+		Message: fmt.Sprintf(msg, args...),
+	})
+}
+
+// fieldKey returns the config key of a struct field, the toml tag if
+// present or the name of the field
+func fieldKey(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("toml"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// lookup finds the key inside values, case-insensitively like the TOML
+// decoder does
+func lookup(values map[string]interface{}, key string) (string, interface{}, bool) {
+	if v, ok := values[key]; ok {
+		return key, v, true
+	}
+	for k, v := range values {
+		if strings.EqualFold(k, key) {
+			return k, v, true
+		}
+	}
+	return "", nil, false
+}
+
+func (cs ConfigSchema) validateStruct(t reflect.Type, values map[string]interface{},
+	prefix string, problems *[]ConfigProblem) {
+	if t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(textUnmarshaler) {
+		return
+	}
+
+	known := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("toml") == "-" {
+			continue
+		}
+
+		name, val, found := lookup(values, fieldKey(f))
+		if found {
+			known[name] = true
+		} else {
+			name = fieldKey(f)
+		}
+		key := prefix + name
+
+		rules := parseRules(f.Tag.Get("config"))
+		if !found {
+			if _, ok := rules["required"]; ok {
+				cs.problem(problems, key, "is required but not found")
+			}
+			continue
+		}
+
+		if !cs.validateType(f.Type, val, key, problems) {
+			continue
+		}
+		cs.validateRules(rules, val, key, problems)
+	}
+
+	if !cs.Strict {
+		return
+	}
+
+	for k := range values {
+		if !known[k] {
+			cs.problem(problems, prefix+k, "is not a known config key")
+		}
+	}
+}
+
+// validateType checks if the value can be decoded into the type and
+// validates the nested tables of structs
+func (cs ConfigSchema) validateType(t reflect.Type, val interface{}, key string,
+	problems *[]ConfigProblem) bool {
+	t = derefType(t)
+	if t.Kind() == reflect.Interface || reflect.PtrTo(t).Implements(textUnmarshaler) {
+		return true
+	}
+
+	ok := true
+	switch t.Kind() {
+	case reflect.String:
+		_, ok = val.(string)
+	case reflect.Bool:
+		_, ok = val.(bool)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, ok = val.(int64)
+	case reflect.Float32, reflect.Float64:
+		switch val.(type) {
+		case int64, float64:
+		default:
+			ok = false
+		}
+	case reflect.Slice, reflect.Array:
+		rv := reflect.ValueOf(val)
+		ok = rv.Kind() == reflect.Slice
+		if ok {
+			for i := 0; i < rv.Len(); i++ {
+				cs.validateType(t.Elem(), rv.Index(i).Interface(),
+					fmt.Sprintf("%s[%d]", key, i), problems)
+			}
+		}
+	case reflect.Map:
+		_, ok = val.(map[string]interface{})
+	case reflect.Struct:
+		var table map[string]interface{}
+		table, ok = val.(map[string]interface{})
+		if ok {
+			cs.validateStruct(t, table, key+".", problems)
+		}
+	}
+
+	if !ok {
+		cs.problem(problems, key, "must be of type %s but found %T", t.Kind(), val)
+	}
+	return ok
+}
+
+func parseRules(tag string) map[string]string {
+	rules := make(map[string]string)
+	for _, r := range strings.Split(tag, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		parts := strings.SplitN(r, "=", 2)
+		if len(parts) == 2 {
+			rules[parts[0]] = parts[1]
+		} else {
+			rules[parts[0]] = ""
+		}
+	}
+	return rules
+}
+
+// measure returns the number compared by min and max, the value of
+// numbers and the length of strings and arrays
+func measure(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		return float64(len(v)), true
+	}
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map {
+		return float64(rv.Len()), true
+	}
+	return 0, false
+}
+
+func (cs ConfigSchema) validateRules(rules map[string]string, val interface{}, key string,
+	problems *[]ConfigProblem) {
+	for _, name := range []string{"min", "max"} {
+		limit, ok := rules[name]
+		if !ok {
+			continue
+		}
+
+		l, err := strconv.ParseFloat(limit, 64)
+		m, measurable := measure(val)
+		if err != nil || !measurable {
+			continue
+		}
+
+		if name == "min" && m < l {
+			cs.problem(problems, key, "must be at least %s but found %v", limit,
+				cs.display(key, val))
+		} else if name == "max" && m > l {
+			cs.problem(problems, key, "must be at most %s but found %v", limit,
+				cs.display(key, val))
+		}
+	}
+
+	if enum, ok := rules["enum"]; ok {
+		allowed := strings.Split(enum, "|")
+		valid := false
+		for _, a := range allowed {
+			if fmt.Sprint(val) == a {
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			cs.problem(problems, key, "must be one of [%s] but found %v",
+				strings.Join(allowed, ", "), cs.display(key, val))
+		}
+	}
+}
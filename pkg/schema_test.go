@@ -0,0 +1,80 @@
+package pkg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type schemaDB struct {
+	Host string `toml:"host" config:"required"`
+	Pool int    `toml:"pool" config:"min=1,max=100"`
+}
+
+type schemaConfig struct {
+	Port     int      `toml:"port" config:"required,min=1,max=65535"`
+	LogLevel string   `toml:"log_level" config:"enum=debug|info|warn"`
+	Database schemaDB `toml:"database"`
+}
+
+func TestConfigSchemaValid(t *testing.T) {
+	values := map[string]interface{}{
+		"port":      int64(8000),
+		"log_level": "info",
+		"database":  map[string]interface{}{"host": "localhost", "pool": int64(10)},
+	}
+
+	schema := ConfigSchema{Strict: true}
+	problems := schema.Validate(reflect.TypeOf(schemaConfig{}), values)
+	if len(problems) != 0 {
+		t.Error("ConfigSchema reported problems for a valid config:", problems)
+	}
+}
+
+func TestConfigSchemaProblems(t *testing.T) {
+	values := map[string]interface{}{
+		"log_level": "verbose",
+		"prot":      int64(8000),
+		"database":  map[string]interface{}{"pool": "ten"},
+	}
+
+	schema := ConfigSchema{
+		Strict:  true,
+		Origins: map[string]string{"log_level": "config/production.toml"},
+	}
+	problems := schema.Validate(reflect.TypeOf(&schemaConfig{}), values)
+
+	expected := map[string]string{
+		"port":          "is required",
+		"log_level":     "must be one of",
+		"prot":          "is not a known config key",
+		"database.host": "is required",
+		"database.pool": "must be of type int",
+	}
+	if len(problems) != len(expected) {
+		t.Fatal("ConfigSchema did not report every problem:", problems)
+	}
+
+	for _, p := range problems {
+		if !strings.Contains(p.Message, expected[p.Key]) {
+			t.Errorf("wrong problem for key %s: %s", p.Key, p.Message)
+		}
+	}
+
+	err := ConfigError{Problems: problems}
+	if !strings.Contains(err.Error(), "config/production.toml: log_level") {
+		t.Error("ConfigError does not contain the source of the key:", err.Error())
+	}
+}
+
+func TestConfigSchemaMinMax(t *testing.T) {
+	values := map[string]interface{}{
+		"port":     int64(70000),
+		"database": map[string]interface{}{"host": "localhost", "pool": int64(0)},
+	}
+
+	problems := ConfigSchema{}.Validate(reflect.TypeOf(schemaConfig{}), values)
+	if len(problems) != 2 {
+		t.Error("ConfigSchema did not validate min and max:", problems)
+	}
+}
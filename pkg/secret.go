@@ -0,0 +1,149 @@
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the value of secrets when the config is printed
+const Redacted = "[REDACTED]"
+
+// secretRef matches ${file:/path/to/secret} and ${env:NAME} references
+var secretRef = regexp.MustCompile(`\$\{(file|env):([^}]+)\}`)
+
+// ResolveSecrets replaces the secret references inside the string values
+// of the config with the secrets they point to. ${file:path} is replaced
+// by the content of the file without the trailing newline and ${env:NAME}
+// by the value of the env variable. It returns the dotted keys of the
+// values which contained a secret, an array is reported by its key
+//
+// 		password = "${file:/run/secrets/db_password}"
+// 		url = "postgres://rubik:${env:DB_PASSWORD}@localhost/rubik"
+func ResolveSecrets(values map[string]interface{}) ([]string, error) {
+	var keys []string
+	err := resolveTable(values, "", &keys)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func resolveTable(table map[string]interface{}, prefix string, keys *[]string) error {
+	for k, v := range table {
+		key := prefix + k
+		if t, ok := v.(map[string]interface{}); ok {
+			if err := resolveTable(t, key+".", keys); err != nil {
+				return err
+			}
+			continue
+		}
+
+		resolved, found, err := resolveValue(v, key, keys)
+		if err != nil {
+			return err
+		}
+		if found {
+			table[k] = resolved
+			*keys = append(*keys, key)
+		}
+	}
+	return nil
+}
+
+// resolveValue resolves the secrets of a non-table value and reports if
+// the value contained any secret
+func resolveValue(v interface{}, key string, keys *[]string) (interface{}, bool, error) {
+	switch val := v.(type) {
+	case string:
+		if !secretRef.MatchString(val) {
+			return val, false, nil
+		}
+		s, err := resolveString(val, key)
+		return s, true, err
+	case []interface{}:
+		found := false
+		for i, x := range val {
+			resolved, ok, err := resolveValue(x, key, keys)
+			if err != nil {
+				return nil, false, err
+			}
+			val[i] = resolved
+			found = found || ok
+		}
+		return val, found, nil
+	case []map[string]interface{}:
+		for i, t := range val {
+			if err := resolveTable(t, fmt.Sprintf("%s[%d].", key, i), keys); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	return v, false, nil
+}
+
+func resolveString(s, key string) (string, error) {
+	var resolveErr error
+	resolved := secretRef.ReplaceAllStringFunc(s, func(ref string) string {
+		m := secretRef.FindStringSubmatch(ref)
+		kind, name := m[1], strings.TrimSpace(m[2])
+		switch kind {
+		case "file":
+			b, err := ioutil.ReadFile(name)
+			if err != nil {
+				resolveErr = fmt.Errorf("ConfigSecretError: cannot read secret file %s "+
+					"of %s: %v", name, key, err)
+				return ""
+			}
+			return strings.TrimRight(string(b), "\r\n")
+		default:
+			val, ok := os.LookupEnv(name)
+			if !ok {
+				resolveErr = fmt.Errorf("ConfigSecretError: env variable %s of %s is not set",
+					name, key)
+				return ""
+			}
+			return val
+		}
+	})
+	return resolved, resolveErr
+}
+
+// RedactValues returns a copy of the config values where the values of the
+// given dotted keys are replaced by Redacted
+func RedactValues(values map[string]interface{}, keys []string) map[string]interface{} {
+	secrets := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		secrets[k] = true
+	}
+	return redactTable(values, "", secrets)
+}
+
+func redactTable(table map[string]interface{}, prefix string,
+	secrets map[string]bool) map[string]interface{} {
+	copied := make(map[string]interface{}, len(table))
+	for k, v := range table {
+		key := prefix + k
+		switch val := v.(type) {
+		case map[string]interface{}:
+			copied[k] = redactTable(val, key+".", secrets)
+		case []map[string]interface{}:
+			tables := make([]map[string]interface{}, len(val))
+			for i, t := range val {
+				tables[i] = redactTable(t, fmt.Sprintf("%s[%d].", key, i), secrets)
+			}
+			copied[k] = tables
+		default:
+			if secrets[key] {
+				copied[k] = Redacted
+			} else {
+				copied[k] = v
+			}
+		}
+	}
+	return copied
+}
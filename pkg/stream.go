@@ -0,0 +1,239 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLogFormat is used when no format is given inside LoggingConfig
+	DefaultLogFormat = "(YYYY/MM/DD hh:mm:ss) [$level] $message"
+	// ErrorLevel is the name of the error log level
+	ErrorLevel = "ERROR"
+	// WarnLevel is the name of the warn log level
+	WarnLevel = "WARN"
+	// InfoLevel is the name of the info log level
+	InfoLevel = "INFO"
+	// DebugLevel is the name of the debug log level
+	DebugLevel = "DEBUG"
+)
+
+var datePlaceholder = regexp.MustCompile(`\(([^)]*)\)`)
+
+var dateLayout = strings.NewReplacer(
+	"YYYY", "2006",
+	"YY", "06",
+	"MM", "01",
+	"DD", "02",
+	"hh", "15",
+	"mm", "04",
+	"ss", "05",
+)
+
+// FormatLog formats the message according to the LoggingConfig format.
+// The date format inside () is written using the YYYY, YY, MM, DD, hh,
+// mm and ss tokens, for example: (DD/MM/YYYY hh:mm)
+func FormatLog(format, level, message string, at time.Time) string {
+	if format == "" {
+		format = DefaultLogFormat
+	}
+
+	out := datePlaceholder.ReplaceAllStringFunc(format, func(m string) string {
+		layout := dateLayout.Replace(m[1 : len(m)-1])
+		return at.Format(layout)
+	})
+	out = strings.ReplaceAll(out, "$level", level)
+	return strings.ReplaceAll(out, "$message", message)
+}
+
+// LogStream writes formatted log lines into the streams defined by
+// a LoggingConfig. It is safe to use from multiple goroutines
+type LogStream struct {
+	mu     sync.Mutex
+	format string
+	out    io.Writer
+	errOut io.Writer
+	files  []*RotatingFile
+}
+
+// NewLogStream creates the LogStream for the given config. $service
+// inside the paths is replaced by the name of the service, when the
+// file stream has no path logs/$service.rubik.info.log and
+// logs/$service.rubik.error.log are used
+func NewLogStream(conf LoggingConfig, service string) (*LogStream, error) {
+	ls := &LogStream{format: conf.Format}
+	switch strings.ToLower(conf.Stream) {
+	case "", "stdout":
+		ls.out = os.Stdout
+		ls.errOut = os.Stdout
+	case "stderr":
+		ls.out = os.Stderr
+		ls.errOut = os.Stderr
+	case "file":
+		path := conf.Path
+		if path == "" {
+			path = filepath.Join("logs", "$service.rubik.info.log")
+		}
+		errPath := conf.ErrorPath
+		if errPath == "" && conf.Path == "" {
+			errPath = filepath.Join("logs", "$service.rubik.error.log")
+		}
+
+		maxSize := int64(conf.MaxSize) * 1024 * 1024
+		maxAge := time.Duration(conf.MaxAge) * time.Hour
+		f, err := NewRotatingFile(strings.ReplaceAll(path, "$service", service),
+			maxSize, maxAge)
+		if err != nil {
+			return nil, err
+		}
+		ls.files = append(ls.files, f)
+		ls.out = f
+		ls.errOut = f
+
+		if errPath != "" {
+			ef, err := NewRotatingFile(strings.ReplaceAll(errPath, "$service", service),
+				maxSize, maxAge)
+			if err != nil {
+				ls.Close()
+				return nil, err
+			}
+			ls.files = append(ls.files, ef)
+			ls.errOut = ef
+		}
+	default:
+		return nil, fmt.Errorf("LoggingConfigError: unknown stream %s, supported streams "+
+			"are stdout, stderr and file", conf.Stream)
+	}
+
+	return ls, nil
+}
+
+// Write writes the message with given level to it's stream
+func (ls *LogStream) Write(level, message string) error {
+	w := ls.out
+	if level == ErrorLevel {
+		w = ls.errOut
+	}
+
+	line := FormatLog(ls.format, level, message, time.Now()) + "\n"
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	_, err := io.WriteString(w, line)
+	return err
+}
+
+// WriteLine writes an already formatted line to the info stream
+func (ls *LogStream) WriteLine(line string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	_, err := io.WriteString(ls.out, line+"\n")
+	return err
+}
+
+// Close flushes and closes all the files opened by the LogStream
+func (ls *LogStream) Close() error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	var err error
+	for _, f := range ls.files {
+		if cerr := f.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// RotatingFile is an io.Writer which writes to a file and moves it
+// to path.TIMESTAMP when it becomes bigger than maxSize bytes or
+// older than maxAge
+type RotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	maxAge  time.Duration
+	file    *os.File
+	size    int64
+	opened  time.Time
+}
+
+// NewRotatingFile opens or creates the file at path for appending
+func NewRotatingFile(path string, maxSize int64, maxAge time.Duration) (*RotatingFile, error) {
+	rf := &RotatingFile{path: path, maxSize: maxSize, maxAge: maxAge}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(rf.path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	rf.file = f
+	rf.size = info.Size()
+	rf.opened = info.ModTime()
+	if rf.size == 0 {
+		rf.opened = time.Now()
+	}
+	return nil
+}
+
+func (rf *RotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+
+	rotated := rf.path + "." + time.Now().Format("20060102T150405.000")
+	if err := os.Rename(rf.path, rotated); err != nil {
+		return err
+	}
+	return rf.open()
+}
+
+// Write writes b to the file rotating it before if needed
+func (rf *RotatingFile) Write(b []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	exceedsSize := rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(b)) > rf.maxSize
+	exceedsAge := rf.maxAge > 0 && time.Since(rf.opened) > rf.maxAge
+	if exceedsSize || exceedsAge {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(b)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Close syncs the content of the file to disk and closes it
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if err := rf.file.Sync(); err != nil {
+		rf.file.Close()
+		return err
+	}
+	return rf.file.Close()
+}
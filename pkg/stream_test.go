@@ -0,0 +1,115 @@
+package pkg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFormatLog(t *testing.T) {
+	at := time.Date(2020, 5, 17, 9, 4, 0, 0, time.UTC)
+	line := FormatLog("$level: (DD/MM/YYYY) $message", InfoLevel, "booted", at)
+	if line != "INFO: 17/05/2020 booted" {
+		t.Error("FormatLog() did not format the log line properly. Line:", line)
+	}
+
+	line = FormatLog("", ErrorLevel, "failed", at)
+	if line != "2020/05/17 09:04:00 [ERROR] failed" {
+		t.Error("FormatLog() did not use the default format. Line:", line)
+	}
+}
+
+func TestNewLogStreamFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rubiklog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ls, err := NewLogStream(LoggingConfig{
+		Stream:    "file",
+		Path:      filepath.Join(dir, "$service.log"),
+		ErrorPath: filepath.Join(dir, "$service.error.log"),
+		Format:    "$level $message",
+	}, "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ls.Write(InfoLevel, "info message")
+	ls.Write(ErrorLevel, "error message")
+	ls.Close()
+
+	info, _ := ioutil.ReadFile(filepath.Join(dir, "svc.log"))
+	if string(info) != "INFO info message\n" {
+		t.Error("LogStream did not write info messages to path. Content:", string(info))
+	}
+
+	errs, _ := ioutil.ReadFile(filepath.Join(dir, "svc.error.log"))
+	if string(errs) != "ERROR error message\n" {
+		t.Error("LogStream did not write error messages to err_path. Content:", string(errs))
+	}
+}
+
+func TestLogStreamConcurrentWrites(t *testing.T) {
+	var buf bytes.Buffer
+	ls := &LogStream{format: "$level $message", out: &buf, errOut: &buf}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ls.Write(InfoLevel, "message")
+		}()
+		go func() {
+			defer wg.Done()
+			ls.WriteLine("GET / 200")
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 40 {
+		t.Fatal("LogStream lost lines written concurrently:", len(lines))
+	}
+	for _, l := range lines {
+		if l != "INFO message" && l != "GET / 200" {
+			t.Error("LogStream mixed lines written concurrently:", l)
+		}
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rubiklog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rf, err := NewRotatingFile(filepath.Join(dir, "app.log"), 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rf.Write([]byte("0123456789"))
+	rf.Write([]byte("rotated"))
+	rf.Close()
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 2 {
+		t.Fatalf("RotatingFile did not rotate when max size was reached, files: %d", len(files))
+	}
+
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), "app.log.") {
+			return
+		}
+	}
+	t.Error("RotatingFile did not move the old file to app.log.TIMESTAMP")
+}
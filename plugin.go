@@ -0,0 +1,235 @@
+package rubik
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// FlagPlugin is a Plugin which accepts flags. Flags registers the flags of
+// the plugin on the FlagSet which is parsed from RUBIK_ARGS before OnPlug
+// is called:
+//
+// 		func (p *Exporter) Flags(fs *flag.FlagSet) {
+// 			fs.StringVar(&p.out, "out", "api.json", "file to write the export to")
+// 		}
+type FlagPlugin interface {
+	Plugin
+	Flags(fs *flag.FlagSet)
+}
+
+// DescribedPlugin is a Plugin which describes itself when the plugins are
+// listed
+type DescribedPlugin interface {
+	Plugin
+	Description() string
+}
+
+// PluginCommand is a subcommand of a plugin selected by the first argument
+// of RUBIK_ARGS. Flags registers the flags of the command and Run is called
+// instead of OnPlug of the plugin when the command is selected
+type PluginCommand struct {
+	Name        string
+	Description string
+	Flags       func(fs *flag.FlagSet)
+	Run         func(app *App) error
+}
+
+// CommandPlugin is a Plugin with subcommands
+type CommandPlugin interface {
+	Plugin
+	Commands() []PluginCommand
+}
+
+// ExitError is returned by a plugin to exit the process with the Code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error implements the error interface of Go
+func (ee ExitError) Error() string {
+	if ee.Err == nil {
+		return fmt.Sprintf("exit status %d", ee.Code)
+	}
+	return ee.Err.Error()
+}
+
+// Unwrap returns the error wrapped by the ExitError
+func (ee ExitError) Unwrap() error {
+	return ee.Err
+}
+
+// Exit returns an ExitError which makes the plugin exit with the code
+func Exit(code int, err error) error {
+	return ExitError{Code: code, Err: err}
+}
+
+// exitCode returns the exit status of the process for the error returned
+// by the plugin, 2 is used for bad arguments like the flag package
+func exitCode(err error) int {
+	var ee ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &ee):
+		return ee.Code
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, new(argsError)):
+		return 2
+	}
+	return 1
+}
+
+// pluginExit exits the process with the exit code of the error returned
+// by the plugin run by Run. An error with exit code 0 like flag.ErrHelp
+// after printing the usage for -h is not returned so that Run can be
+// used with log.Fatal or panic
+func pluginExit(err error) error {
+	if code := exitCode(err); code != 0 {
+		fmt.Fprintln(os.Stderr, err)
+		exit(code)
+		return err
+	}
+	return nil
+}
+
+// argsError is returned when the arguments of the plugin cannot be parsed
+type argsError struct {
+	err error
+}
+
+func (ae argsError) Error() string {
+	return "PluginArgsError: " + ae.err.Error()
+}
+
+// exit is replaced in tests to not exit the test binary
+var exit = os.Exit
+
+// duplicatePlugins holds the RunIDs used by more than one plugin
+var duplicatePlugins []string
+
+// splitArgs splits the raw RUBIK_ARGS into arguments like a shell does,
+// single and double quotes group words and backslash escapes a character
+func splitArgs(raw string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range raw {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in arguments", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// parsePluginArgs parses the arguments for the plugin and returns the
+// selected command if any. The parsed FlagSet is set on the App
+func parsePluginArgs(plugin Plugin, sb *App, args []string, out io.Writer) (*PluginCommand, error) {
+	name := plugin.RunID()
+	var cmd *PluginCommand
+	if cp, ok := plugin.(CommandPlugin); ok && len(args) > 0 &&
+		!strings.HasPrefix(args[0], "-") {
+		for _, c := range cp.Commands() {
+			if c.Name == args[0] {
+				c := c
+				cmd = &c
+				break
+			}
+		}
+
+		if cmd == nil {
+			return nil, argsError{fmt.Errorf("%s has no command named %s", name, args[0])}
+		}
+		name += " " + cmd.Name
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(out)
+	if cmd != nil {
+		if cmd.Flags != nil {
+			cmd.Flags(fs)
+		}
+	} else if fp, ok := plugin.(FlagPlugin); ok {
+		fp.Flags(fs)
+	}
+
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil, err
+	} else if err != nil {
+		return nil, argsError{err}
+	}
+
+	sb.FlagSet = fs
+	sb.Command = name
+	sb.Positional = fs.Args()
+	return cmd, nil
+}
+
+// listPlugins writes the plugged plugins along with their descriptions
+// and commands
+func listPlugins(w io.Writer) {
+	plugins := availablePlugins()
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].RunID() < plugins[j].RunID()
+	})
+
+	fmt.Fprintln(w, "Plugins plugged in this project:")
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, p := range plugins {
+		desc := p.Name()
+		if dp, ok := p.(DescribedPlugin); ok {
+			desc = dp.Description()
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", p.RunID(), desc)
+
+		if cp, ok := p.(CommandPlugin); ok {
+			for _, c := range cp.Commands() {
+				fmt.Fprintf(tw, "    %s %s\t%s\n", p.RunID(), c.Name, c.Description)
+			}
+		}
+	}
+	tw.Flush()
+}
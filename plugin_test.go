@@ -0,0 +1,129 @@
+package rubik
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+type testPlugin struct {
+	out     string
+	verbose bool
+	ran     string
+}
+
+func (tp *testPlugin) OnPlug(app *App) error { return nil }
+func (tp *testPlugin) Name() string          { return "Test Plugin" }
+func (tp *testPlugin) RunID() string         { return "test" }
+func (tp *testPlugin) Description() string   { return "exports the routes" }
+
+func (tp *testPlugin) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&tp.out, "out", "api.json", "output file")
+}
+
+func (tp *testPlugin) Commands() []PluginCommand {
+	return []PluginCommand{{
+		Name:        "sync",
+		Description: "syncs the routes",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&tp.verbose, "v", false, "verbose output")
+		},
+		Run: func(app *App) error {
+			tp.ran = app.Command
+			return nil
+		},
+	}}
+}
+
+func TestSplitArgs(t *testing.T) {
+	args, err := splitArgs(`-out "my file.json" --name='a b' c\ d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(args, "|") != "-out|my file.json|--name=a b|c d" {
+		t.Error("splitArgs() returned", args)
+	}
+
+	if _, err := splitArgs(`-out "file`); err == nil {
+		t.Error("splitArgs() did not report unterminated quote")
+	}
+}
+
+func TestParsePluginArgs(t *testing.T) {
+	tp := &testPlugin{}
+	sb := &App{}
+	cmd, err := parsePluginArgs(tp, sb, []string{"-out", "routes.json", "extra"}, ioutil.Discard)
+	if err != nil || cmd != nil {
+		t.Fatal("parsePluginArgs() returned", cmd, err)
+	}
+
+	if tp.out != "routes.json" || len(sb.Positional) != 1 || sb.Positional[0] != "extra" {
+		t.Error("parsePluginArgs() did not parse flags:", tp.out, sb.Positional)
+	}
+
+	cmd, err = parsePluginArgs(tp, sb, []string{"sync", "-v"}, ioutil.Discard)
+	if err != nil || cmd == nil || !tp.verbose {
+		t.Fatal("parsePluginArgs() did not select the command:", cmd, err)
+	}
+
+	if err := cmd.Run(sb); err != nil || tp.ran != "test sync" {
+		t.Error("command did not run with the parsed App:", tp.ran)
+	}
+
+	_, err = parsePluginArgs(tp, sb, []string{"-unknown"}, ioutil.Discard)
+	if exitCode(err) != 2 {
+		t.Error("parsePluginArgs() did not return args error for unknown flag:", err)
+	}
+
+	_, err = parsePluginArgs(tp, sb, []string{"deploy"}, ioutil.Discard)
+	if exitCode(err) != 2 {
+		t.Error("parsePluginArgs() did not return args error for unknown command:", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if exitCode(nil) != 0 || exitCode(errors.New("failed")) != 1 ||
+		exitCode(Exit(3, errors.New("drift found"))) != 3 || exitCode(flag.ErrHelp) != 0 {
+		t.Error("exitCode() returned wrong exit status")
+	}
+}
+
+func TestPluginExit(t *testing.T) {
+	defer func() { exit = os.Exit }()
+	code := -1
+	exit = func(c int) { code = c }
+
+	if err := pluginExit(flag.ErrHelp); err != nil || code != -1 {
+		t.Error("pluginExit() did not treat -h as success:", err, code)
+	}
+
+	if err := pluginExit(Exit(3, errors.New("drift found"))); err == nil || code != 3 {
+		t.Error("pluginExit() did not exit with the code of the plugin:", err, code)
+	}
+}
+
+func TestPlugDuplicate(t *testing.T) {
+	oldExtensions := app.extensions
+	defer func() {
+		app.extensions = oldExtensions
+		duplicatePlugins = nil
+	}()
+
+	Plug(&testPlugin{})
+	Plug(&testPlugin{})
+	if len(app.extensions) != len(oldExtensions)+1 || len(duplicatePlugins) != 1 {
+		t.Error("Plug() did not detect the duplicate RunID")
+	}
+
+	var buf bytes.Buffer
+	listPlugins(&buf)
+	if !strings.Contains(buf.String(), "exports the routes") ||
+		!strings.Contains(buf.String(), "test sync") {
+		t.Error("listPlugins() did not list the plugin:", buf.String())
+	}
+}
@@ -61,7 +61,13 @@ func populateHTTPRequest(req *Payload, fullURL string) (*http.Request, error) {
 	var requestBody []byte
 	var httpRequest *http.Request
 
-	if req.json && len(req.body) > 0 {
+	if req.gob {
+		httpRequest, err = http.NewRequest(req.requestType, fullURL, bytes.NewReader(req.rawBody))
+		if err != nil {
+			return nil, err
+		}
+		httpRequest.Header.Set(Content.Header, Content.Gob)
+	} else if req.json && len(req.body) > 0 {
 		requestBody, err = json.Marshal(req.body)
 		httpRequest, err = http.NewRequest(req.requestType, fullURL, bytes.NewBuffer(requestBody))
 		if err != nil {
@@ -38,6 +38,11 @@ type TestProbe struct {
 //		}
 func NewProbe(ro Router) *TestProbe {
 	os.Setenv("RUBIK_ENV", "test")
+	// keep the test output clean of the boot messages, the probe gets its
+	// own logger so that the level of pkg.DefaultLogger is not changed
+	if app.logger == pkg.DefaultLogger {
+		app.logger = pkg.NewLogger(os.Stdout, os.Getenv("RUBIK_LOG_FORMAT"), pkg.LevelWarn)
+	}
 	var a = make(map[string]interface{})
 	// use the router in this package
 	Use(ro)
@@ -75,7 +80,7 @@ func (probe *TestProbe) fetchResponse(entity TestableEntity) *httptest.ResponseR
 	r := probe.getRouteFromEntity(entity)
 	pathSuffix := safeRoutePath(entity.Path())
 	if entity.Path() == "" {
-		pkg.DebugMsg("Test | entity.PointTo is empty, using / as the endpoint locator")
+		probe.app.logger.Debug("Test | entity.PointTo is empty, using / as the endpoint locator")
 		pathSuffix = "/"
 	}
 
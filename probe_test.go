@@ -1,8 +1,11 @@
 package rubik
 
 import (
+	"os"
 	"reflect"
 	"testing"
+
+	"github.com/rubikorg/rubik/pkg"
 )
 
 var probe *TestProbe
@@ -66,3 +69,13 @@ func TestIndexRoute(t *testing.T) {
 		}
 	}
 }
+
+func TestProbeLogger(t *testing.T) {
+	if probe.app.logger == pkg.DefaultLogger || probe.app.logger.Enabled(pkg.LevelInfo) {
+		t.Error("NewProbe() did not use its own logger at level warn")
+	}
+
+	if os.Getenv("RUBIK_LOG_LEVEL") == "" && !pkg.DefaultLogger.Enabled(pkg.LevelInfo) {
+		t.Error("NewProbe() changed the level of pkg.DefaultLogger")
+	}
+}
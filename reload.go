@@ -0,0 +1,213 @@
+package rubik
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rubikorg/rubik/pkg"
+)
+
+// ConfigChangeListener can be implemented by a Block to be notified when
+// the config is reloaded, old and new are values of the config struct
+// which was passed to rubik.Load
+type ConfigChangeListener interface {
+	OnConfigChange(old, new interface{})
+}
+
+var configWatchInterval time.Duration
+var configListeners []func(old, new interface{})
+var listenerMu sync.Mutex
+var restartOnlyKeys = []string{"host", "port"}
+
+// WatchConfig enables the hot reload of your config. The config files are
+// checked for changes every interval and when they change the config is
+// loaded again and swapped without restarting the server. Only the config
+// files on disk are watched
+//
+// 		rubik.WatchConfig(time.Second * 2)
+func WatchConfig(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	configWatchInterval = interval
+}
+
+// OnConfigChange registers a function which is called with the old and
+// the new config whenever the config is reloaded
+func OnConfigChange(fn func(old, new interface{})) {
+	listenerMu.Lock()
+	defer listenerMu.Unlock()
+	configListeners = append(configListeners, fn)
+}
+
+// RestartOnly marks the dotted config keys which cannot be changed while
+// the server is running. When a reload changes these keys the old value
+// is kept and a warning is logged. host and port are always restart-only
+func RestartOnly(keys ...string) {
+	restartOnlyKeys = append(restartOnlyKeys, keys...)
+}
+
+// configSignature returns a string which changes when any of the config
+// files on disk is modified, created or removed
+func configSignature(env string) string {
+	var sb strings.Builder
+	for _, src := range getConfigSources() {
+		fsrc, ok := src.(FileSource)
+		if !ok || fsrc.FS != nil {
+			continue
+		}
+
+		for _, e := range []string{"default", env} {
+			for _, ext := range pkg.ConfigExtensions {
+				p := filepath.Join(fsrc.Dir, e+ext)
+				info, err := os.Stat(p)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(&sb, "%s:%d:%d;", p, info.ModTime().UnixNano(), info.Size())
+			}
+		}
+	}
+	return sb.String()
+}
+
+// watchConfig polls the config files and reloads the config when they
+// change until stop is closed
+func watchConfig(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := configSignature(app.currentEnv)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			sig := configSignature(app.currentEnv)
+			if sig == last {
+				continue
+			}
+			last = sig
+
+			if err := reloadConfig(); err != nil {
+				app.logger.Error("ConfigReloadError: keeping the old config", "error", err)
+			}
+		}
+	}
+}
+
+// reloadConfig merges the config again and swaps it with the current
+// config of rubik. The restart-only keys keep their old values
+func reloadConfig() error {
+	configMu.RLock()
+	typ := app.configType
+	oldValues := app.configMap
+	oldSecrets := app.secretKeys
+	old := app.config
+	configMu.RUnlock()
+
+	// Load was never called so there is nothing to reload
+	if typ == nil {
+		return nil
+	}
+
+	values, origins, found, err := mergeConfig(app.currentEnv, typ)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		values = make(map[string]interface{})
+	}
+
+	// the secrets are resolved first so that the restart-only values are
+	// compared with the resolved old values
+	secrets, err := pkg.ResolveSecrets(values)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range restartOnlyKeys {
+		path := strings.Split(key, ".")
+		oldVal, oldOk := pkg.GetValue(oldValues, path)
+		newVal, newOk := pkg.GetValue(values, path)
+		if oldOk == newOk && reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+
+		app.logger.Warn("ConfigReload: key can only be changed by restarting the server",
+			"key", key)
+		if oldOk {
+			pkg.SetValue(values, path, oldVal)
+		} else {
+			pkg.DeleteValue(values, path)
+		}
+		secrets = keepSecretKeys(secrets, oldSecrets, key)
+	}
+
+	target := reflect.New(typ)
+	nm, err := decodeConfig(target.Interface(), values, origins, secrets)
+	if err != nil {
+		return err
+	}
+	config := target.Elem().Interface()
+
+	configMu.Lock()
+	app.intermConfig = nm
+	app.configMap = values
+	app.secretKeys = secrets
+	app.config = config
+	configMu.Unlock()
+
+	app.logger.Info("ConfigReload: config reloaded", "env", app.currentEnv)
+	notifyConfigChange(old, config)
+	return nil
+}
+
+// keepSecretKeys replaces the secret keys at or under the restart-only key
+// with the old secret keys as the old value of the key is kept
+func keepSecretKeys(secrets, oldSecrets []string, key string) []string {
+	under := func(k string) bool {
+		return k == key || strings.HasPrefix(k, key+".")
+	}
+
+	var keys []string
+	for _, k := range secrets {
+		if !under(k) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range oldSecrets {
+		if under(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// notifyConfigChange calls the OnConfigChange functions and the blocks
+// which implement ConfigChangeListener
+func notifyConfigChange(old, new interface{}) {
+	listenerMu.Lock()
+	listeners := append([]func(old, new interface{}){}, configListeners...)
+	listenerMu.Unlock()
+
+	for _, fn := range listeners {
+		fn(old, new)
+	}
+
+	for _, blocks := range []map[string]Block{app.blocks, app.afterBlocks} {
+		for _, b := range blocks {
+			if l, ok := b.(ConfigChangeListener); ok {
+				l.OnConfigChange(old, new)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package rubik
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rubikorg/rubik/pkg"
+)
+
+type reloadTestConfig struct {
+	Host  string `toml:"host"`
+	Port  string `toml:"port"`
+	Limit int    `toml:"limit"`
+}
+
+type reloadBlock struct {
+	limit int
+}
+
+func (rb *reloadBlock) OnAttach(app *App) error { return nil }
+
+func (rb *reloadBlock) OnConfigChange(old, new interface{}) {
+	rb.limit = new.(reloadTestConfig).Limit
+}
+
+func TestReloadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rubik-reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "default.toml")
+	write := func(content string) {
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("host = \"localhost\"\nport = \"8000\"\nlimit = 10\n")
+
+	oldSources, oldEnv, oldConfig := configSources, app.currentEnv, app.config
+	oldMap, oldType, oldNM := app.configMap, app.configType, app.intermConfig
+	defer func() {
+		configSources, app.currentEnv, app.config = oldSources, oldEnv, oldConfig
+		app.configMap, app.configType, app.intermConfig = oldMap, oldType, oldNM
+		delete(app.blocks, "reloader")
+		configListeners = nil
+	}()
+
+	UseConfigSource(FileSource{Dir: dir})
+	app.currentEnv = "default"
+	var conf reloadTestConfig
+	if err := Load(&conf); err != nil {
+		t.Fatal(err)
+	}
+
+	rb := &reloadBlock{}
+	app.blocks["reloader"] = rb
+	var oldLimit int
+	OnConfigChange(func(old, new interface{}) {
+		oldLimit = old.(reloadTestConfig).Limit
+	})
+
+	before := configSignature("default")
+	write("host = \"localhost\"\nport = \"9000\"\nlimit = 25\n")
+	if err := reloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if oldLimit != 10 || rb.limit != 25 {
+		t.Errorf("listeners got old %d and new %d, want 10 and 25", oldLimit, rb.limit)
+	}
+
+	current := GetConfig().(reloadTestConfig)
+	if current.Port != "8000" {
+		t.Errorf("restart-only port changed to %s", current.Port)
+	}
+
+	if app.notationMap().Get("limit") != int64(25) {
+		t.Errorf("limit not swapped: %v", app.notationMap().Get("limit"))
+	}
+
+	if configSignature("default") == before {
+		t.Error("config signature did not change after writing the file")
+	}
+
+	write("limit = \"many\"\n")
+	if err := reloadConfig(); err == nil {
+		t.Error("expected reload of invalid config to fail")
+	}
+	if GetConfig().(reloadTestConfig).Limit != 25 {
+		t.Error("old config was not kept after failed reload")
+	}
+}
+
+type reloadSecretConfig struct {
+	Host string `toml:"host"`
+	Port string `toml:"port"`
+	DB   struct {
+		Pass string `toml:"pass"`
+	} `toml:"db"`
+	Limit int `toml:"limit"`
+}
+
+func TestReloadRestartOnlySecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rubik-reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "default.toml")
+	write := func(limit int) {
+		content := fmt.Sprintf("host = \"localhost\"\nport = \"8000\"\nlimit = %d\n"+
+			"[db]\npass = \"${env:RUBIK_TEST_RELOAD_PASS}\"\n", limit)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(10)
+	os.Setenv("RUBIK_TEST_RELOAD_PASS", "s3cret")
+	defer os.Unsetenv("RUBIK_TEST_RELOAD_PASS")
+
+	oldSources, oldEnv, oldConfig := configSources, app.currentEnv, app.config
+	oldMap, oldType, oldNM := app.configMap, app.configType, app.intermConfig
+	oldSecrets, oldKeys, oldLogger := app.secretKeys, restartOnlyKeys, app.logger
+	defer func() {
+		configSources, app.currentEnv, app.config = oldSources, oldEnv, oldConfig
+		app.configMap, app.configType, app.intermConfig = oldMap, oldType, oldNM
+		app.secretKeys, restartOnlyKeys, app.logger = oldSecrets, oldKeys, oldLogger
+	}()
+
+	var logs bytes.Buffer
+	app.logger = pkg.NewLogger(&logs, pkg.FormatText, pkg.LevelInfo)
+	UseConfigSource(FileSource{Dir: dir})
+	app.currentEnv = "default"
+	RestartOnly("db.pass")
+	var conf reloadSecretConfig
+	if err := Load(&conf); err != nil {
+		t.Fatal(err)
+	}
+
+	write(20)
+	if err := reloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(logs.String(), "key=db.pass") {
+		t.Error("unchanged secret was reported as changed:", logs.String())
+	}
+
+	os.Setenv("RUBIK_TEST_RELOAD_PASS", "changed")
+	write(30)
+	if err := reloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "key=db.pass") {
+		t.Error("changed secret was not reported:", logs.String())
+	}
+
+	current := GetConfig().(reloadSecretConfig)
+	if current.DB.Pass != "s3cret" || current.Limit != 30 {
+		t.Errorf("reload kept pass %q and limit %d, want s3cret and 30", current.DB.Pass,
+			current.Limit)
+	}
+
+	db := RedactedConfig()["db"].(map[string]interface{})
+	if db["pass"] != pkg.Redacted {
+		t.Error("restored secret is not redacted:", db["pass"])
+	}
+}
@@ -2,12 +2,14 @@ package rubik
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/rubikorg/rubik/pkg"
 	"github.com/rubikorg/rubik/replc"
 )
@@ -21,6 +23,7 @@ var cmdMap = map[string]func([]string) string{
 	"exit":   exitCommand,
 	"help":   replc.HelpCommand,
 	"list":   replc.ListCommand,
+	"config": configCommand,
 }
 
 // because we need to keep the project information on the root
@@ -63,6 +66,16 @@ func selectCommand(args []string) string {
 	return fmt.Sprintf("Selected project: %s", name)
 }
 
+// configCommand prints the loaded config of the app with the secrets
+// redacted
+func configCommand(args []string) string {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(RedactedConfig()); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
 func exitCommand(args []string) string {
 	fmt.Println("Bye!")
 	os.Exit(0)
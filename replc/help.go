@@ -59,6 +59,11 @@ var replCommands = []cmd{
 		desc:  "finds the route that likely matches your argument",
 		usage: "find [route]",
 	},
+	{
+		name:  "config",
+		desc:  "prints the config of the project with secrets redacted",
+		usage: "config",
+	},
 	{
 		name:  "exit",
 		desc:  "exit the okrubik repl",
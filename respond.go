@@ -0,0 +1,99 @@
+package rubik
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+)
+
+// ResponseBuilder sets the status and the headers of the response before
+// it is written by Respond
+//
+// 		req.Status(201).Header("Location", "/users/"+id).Respond(user)
+// 		req.Status(204).Respond(nil)
+type ResponseBuilder struct {
+	req    *Request
+	status int
+}
+
+// Status returns a ResponseBuilder which responds with the status code
+func (req *Request) Status(status int) *ResponseBuilder {
+	return &ResponseBuilder{req: req, status: status}
+}
+
+// Status changes the status code of the response
+func (rb *ResponseBuilder) Status(status int) *ResponseBuilder {
+	rb.status = status
+	return rb
+}
+
+// Header sets the header of the response replacing its values
+func (rb *ResponseBuilder) Header(key, value string) *ResponseBuilder {
+	rb.req.Writer.Header().Set(key, value)
+	return rb
+}
+
+// Respond writes the data with the status and the headers of the builder,
+// the data is ignored for the statuses which cannot have a body like 204
+// and 304. See Request.Respond for the types
+func (rb *ResponseBuilder) Respond(data interface{}, ofType ...ByteType) {
+	rb.req.respond(rb.status, data, defByteType(ofType))
+}
+
+// bodyAllowed reports whether a response with the status can have a body
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != 204 && status != 304
+}
+
+// writeBody writes the body of the JSON, Text and HTML types with the
+// status, the Content-Type set before using ResponseBuilder.Header is kept
+// otherwise ctype is sent. Negotiated bodies always use their media type
+func (req *Request) writeBody(status int, ctype string, body []byte) {
+	if set := req.Writer.Header().Get(Content.Header); set != "" {
+		ctype = set
+	}
+	writeResponse(&req.Writer, status, ctype, body)
+}
+
+// respondBytes writes []byte, string or io.Reader data as it is, the
+// Content-Type header is kept if it was set otherwise the data is sent
+// as application/octet-stream
+func (req *Request) respondBytes(status int, data interface{}) {
+	ctype := req.Writer.Header().Get(Content.Header)
+	if ctype == "" {
+		ctype = Content.Bytes
+	}
+
+	switch v := data.(type) {
+	case []byte:
+		writeResponse(&req.Writer, status, ctype, v)
+	case string:
+		writeResponse(&req.Writer, status, ctype, []byte(v))
+	case io.Reader:
+		if rc, ok := v.(io.Closer); ok {
+			defer rc.Close()
+		}
+		req.Writer.Header().Set(Content.Header, ctype)
+		req.Writer.WriteHeader(status)
+		io.Copy(&req.Writer, v)
+	default:
+		req.Throw(500, E("Error: cannot be written as Bytes"))
+	}
+}
+
+func encodeBytes(req *Request, data interface{}) ([]byte, error) {
+	if b, ok := data.([]byte); ok {
+		return b, nil
+	}
+	return nil, ErrCannotEncode
+}
+
+// encodeGob encodes the data with encoding/gob, the concrete types held
+// by interface values must be registered with gob.Register
+func encodeGob(req *Request, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
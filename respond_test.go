@@ -0,0 +1,139 @@
+package rubik
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type gobUser struct {
+	Entity
+	ID   string `rubik:"id|param"`
+	Name string `rubik:"name|body!"`
+	Age  int    `rubik:"age|body"`
+}
+
+func responseRequest() (*Request, *httptest.ResponseRecorder) {
+	rr := httptest.NewRecorder()
+	return &Request{Raw: httptest.NewRequest("GET", "/", nil),
+		Writer: RResponseWriter{ResponseWriter: rr}}, rr
+}
+
+func TestResponseBuilder(t *testing.T) {
+	req, rr := responseRequest()
+	req.Status(201).Header("Location", "/users/1").Respond(map[string]string{"id": "1"})
+	if rr.Code != 201 || rr.Header().Get("Location") != "/users/1" ||
+		rr.Header().Get(Content.Header) != Content.JSON ||
+		strings.TrimSpace(rr.Body.String()) != `{"id":"1"}` {
+		t.Error("Respond() did not use the status and headers:", rr.Code, rr.Header(), rr.Body)
+	}
+
+	req, rr = responseRequest()
+	req.Status(200).Status(204).Respond("ignored", Type.Text)
+	if rr.Code != 204 || rr.Body.Len() != 0 {
+		t.Error("Respond() wrote a body with 204:", rr.Code, rr.Body.String())
+	}
+}
+
+func TestResponseBuilderContentType(t *testing.T) {
+	const ctype = "application/vnd.api+json"
+	for _, ty := range []ByteType{Type.JSON, Type.Text, Type.HTML} {
+		req, rr := responseRequest()
+		req.Status(201).Header(Content.Header, ctype).Respond("data", ty)
+		if rr.Code != 201 || rr.Header().Get(Content.Header) != ctype {
+			t.Errorf("Respond() with type %d did not keep the Content-Type: %d %v", ty,
+				rr.Code, rr.Header())
+		}
+	}
+
+	// the negotiated media type is sent even if a Content-Type was set
+	req, rr := responseRequest()
+	req.Raw.Header.Set("Accept", Content.XML)
+	req.Status(200).Header(Content.Header, Content.JSON).Respond("data", Type.Auto)
+	if rr.Header().Get(Content.Header) != Content.XML {
+		t.Error("Respond() did not label the negotiated body:", rr.Header())
+	}
+
+	req, rr = responseRequest()
+	req.Status(201).Header(Content.Header, ctype).Respond(make(chan int))
+	if rr.Code != 500 || len(rr.Header().Values(Content.Header)) != 1 {
+		t.Error("Respond() did not send the encoding error as 500:", rr.Code, rr.Header())
+	}
+}
+
+func TestRespondBytes(t *testing.T) {
+	req, rr := responseRequest()
+	req.Respond([]byte{1, 2, 3}, Type.Bytes)
+	if rr.Header().Get(Content.Header) != Content.Bytes || !bytes.Equal(rr.Body.Bytes(), []byte{1, 2, 3}) {
+		t.Error("Respond() did not write the bytes:", rr.Header(), rr.Body.Bytes())
+	}
+
+	req, rr = responseRequest()
+	req.Status(202).Header(Content.Header, "image/png").
+		Respond(strings.NewReader("png"), Type.Bytes)
+	if rr.Code != 202 || rr.Header().Get(Content.Header) != "image/png" || rr.Body.String() != "png" {
+		t.Error("Respond() did not write the reader:", rr.Code, rr.Header(), rr.Body.String())
+	}
+
+	req, rr = responseRequest()
+	req.Respond(42, Type.Bytes)
+	if rr.Code != 500 {
+		t.Error("Respond() did not reject the data:", rr.Code)
+	}
+}
+
+func TestRespondGob(t *testing.T) {
+	req, rr := responseRequest()
+	req.Respond(gobUser{Name: "rubik", Age: 3}, Type.Gob)
+
+	var u gobUser
+	if err := gob.NewDecoder(rr.Body).Decode(&u); err != nil || u.Name != "rubik" || u.Age != 3 {
+		t.Error("Respond() did not write gob:", err, u)
+	}
+	if rr.Header().Get(Content.Header) != Content.Gob {
+		t.Error("Respond() did not set the gob Content-Type:", rr.Header())
+	}
+}
+
+func TestGobRequest(t *testing.T) {
+	en := gobUser{Name: "rubik", Age: 3}
+	en.PointTo = "/users/$"
+	en.Params = []string{"1"}
+	en.Gob = true
+
+	payload, err := extract(en)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload.requestType = "POST"
+	payload.context = context.Background()
+	raw, err := populateHTTPRequest(&payload, "http://localhost/users/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw.Header.Get(Content.Header) != Content.Gob {
+		t.Fatal("populateHTTPRequest() did not send gob:", raw.Header)
+	}
+
+	req := httptest.NewRequest("POST", "/users/1", raw.Body)
+	req.Header.Set(Content.Header, Content.Gob)
+	injected, err := inject(req, nil, &gobUser{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := injected.(*gobUser); u.Name != "rubik" || u.Age != 3 || u.PointTo != "" {
+		t.Error("inject() did not decode the gob body:", u)
+	}
+
+	var empty bytes.Buffer
+	gob.NewEncoder(&empty).Encode(gobUser{Age: 3})
+	req = httptest.NewRequest("POST", "/users/1", &empty)
+	req.Header.Set(Content.Header, Content.Gob)
+	if _, err := inject(req, nil, &gobUser{}, nil); err == nil {
+		t.Error("inject() did not require the body field")
+	}
+}
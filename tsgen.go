@@ -0,0 +1,428 @@
+package rubik
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TypeScriptOptions configures the TypeScript client generated by
+// GenerateTypeScript. All includes the routes which are not marked with
+// Export
+type TypeScriptOptions struct {
+	All bool
+}
+
+var fileType = reflect.TypeOf(File{})
+
+// tsTypes holds the interfaces of the generated TypeScript file, entities
+// are described by their rubik keys and responses by their json tags
+type tsTypes struct {
+	names    map[reflect.Type]string
+	entities map[reflect.Type]string
+	taken    map[string]bool
+	decls    []string
+}
+
+// name reserves a unique name for an interface
+func (tt *tsTypes) name(base string) string {
+	if base == "" {
+		base = "Anonymous"
+	}
+
+	name := base
+	for i := 2; tt.taken[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	tt.taken[name] = true
+	return name
+}
+
+// typeOf returns the TypeScript type of a Go type and declares the
+// interfaces of the named structs it uses
+func (tt *tsTypes) typeOf(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes bytes as base64 strings
+			return "string"
+		}
+		elem := tt.typeOf(t.Elem())
+		if strings.ContainsAny(elem, " |") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		return "Record<string, " + tt.typeOf(t.Elem()) + ">"
+	case reflect.Struct:
+		switch t {
+		case timeType:
+			return "string"
+		case fileType:
+			return "Blob"
+		}
+		if t.Name() == "" {
+			return tt.structBody(t, "")
+		}
+
+		if name, ok := tt.names[t]; ok {
+			return name
+		}
+		name := tt.name(t.Name())
+		tt.names[t] = name
+		tt.decls = append(tt.decls, "export interface "+name+" "+tt.structBody(t, "")+"\n")
+		return name
+	}
+	return "unknown"
+}
+
+// structBody returns the members of the struct named by their json tags
+func (tt *tsTypes) structBody(t reflect.Type, indent string) string {
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	tt.writeMembers(&sb, t, indent+"  ")
+	sb.WriteString(indent + "}")
+	return sb.String()
+}
+
+func (tt *tsTypes) writeMembers(sb *strings.Builder, t reflect.Type, indent string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Name
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		} else if tag[0] != "" {
+			name = tag[0]
+		}
+
+		if f.Anonymous && tag[0] == "" && f.Type.Kind() == reflect.Struct {
+			tt.writeMembers(sb, f.Type, indent)
+			continue
+		}
+
+		optional := ""
+		if strings.Contains(f.Tag.Get("json"), "omitempty") || f.Type.Kind() == reflect.Ptr {
+			optional = "?"
+		}
+		fmt.Fprintf(sb, "%s%s%s: %s;\n", indent, tsKey(name), optional, tt.typeOf(f.Type))
+	}
+}
+
+// entity declares the interface of the entity which holds the fields
+// injected from the request named by their rubik keys
+func (tt *tsTypes) entity(r APIRoute) string {
+	if r.EntityType != nil {
+		if name, ok := tt.entities[r.EntityType]; ok {
+			return name
+		}
+	}
+
+	base := r.Entity
+	if base == "" {
+		base = capitalize(operationID(r.Method, r.Path)) + "Entity"
+	}
+	name := tt.name(base)
+	if r.EntityType != nil {
+		tt.entities[r.EntityType] = name
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "export interface %s {\n", name)
+	for _, f := range r.Fields {
+		optional := "?"
+		if f.Required {
+			optional = ""
+		}
+
+		typ := "unknown"
+		if f.GoType != nil {
+			typ = tt.typeOf(f.GoType)
+		}
+		fmt.Fprintf(&sb, "  %s%s: %s;\n", tsKey(f.Key), optional, typ)
+	}
+	sb.WriteString("}\n")
+	tt.decls = append(tt.decls, sb.String())
+	return name
+}
+
+// tsKey quotes the member names which are not identifiers
+func tsKey(key string) string {
+	for i, r := range key {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+			i > 0 && r >= '0' && r <= '9') {
+			return strconv.Quote(key)
+		}
+	}
+	return key
+}
+
+// tsReserved are the words which cannot be used as parameter names
+var tsReserved = map[string]bool{
+	"en": true, "init": true, "config": true, "class": true, "default": true,
+	"delete": true, "function": true, "new": true, "this": true, "var": true,
+	"let": true, "const": true, "in": true, "import": true, "export": true,
+}
+
+// tsFunction returns the function of the client which calls the route
+func (tt *tsTypes) tsFunction(r APIRoute, name string) string {
+	var args []string
+	args = append(args, "config: ClientConfig")
+
+	hasEntity := r.EntityType != nil || len(r.Fields) > 0
+	// path parameters are read from the param fields of the entity or
+	// passed as arguments of the function
+	_, pathParams := openAPIPath(r.Path)
+	var pathArgs []string
+	for _, p := range pathParams {
+		value := ""
+		for _, f := range r.Fields {
+			if f.In == "param" && strings.EqualFold(f.Key, p) {
+				value = "en" + tsAccess(f.Key)
+				break
+			}
+		}
+
+		if value == "" {
+			value = goIdent(p)
+			if tsReserved[value] {
+				value += "Param"
+			}
+			args = append(args, value+": string | number")
+		}
+		pathArgs = append(pathArgs, value)
+	}
+
+	if hasEntity {
+		args = append(args, "en: "+tt.entity(r))
+	}
+	args = append(args, "init?: RequestInit")
+
+	result := "unknown"
+	if t, ok := successType(r); ok {
+		result = tt.typeOf(t)
+	}
+
+	i := 0
+	path := paramSyntax.ReplaceAllStringFunc(r.Path, func(string) string {
+		s := "${encodeURIComponent(String(" + pathArgs[i] + "))}"
+		i++
+		return s
+	})
+
+	parts := map[string][]string{}
+	for _, f := range r.Fields {
+		if f.In == "param" {
+			continue
+		}
+		parts[f.In] = append(parts[f.In], fmt.Sprintf("%s: en%s", tsKey(f.Key), tsAccess(f.Key)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("/**\n")
+	fmt.Fprintf(&sb, " * %s %s\n", r.Method, r.Path)
+	if r.Description != "" {
+		fmt.Fprintf(&sb, " *\n * %s\n", r.Description)
+	}
+	sb.WriteString(" */\n")
+	fmt.Fprintf(&sb, "export function %s(%s): Promise<%s> {\n", name, strings.Join(args, ", "), result)
+	fmt.Fprintf(&sb, "  return request<%s>(config, %q, `%s`, {", result, r.Method, path)
+	var members []string
+	for _, in := range []string{"query", "body", "form"} {
+		if len(parts[in]) > 0 {
+			members = append(members, fmt.Sprintf("%s: { %s }", in, strings.Join(parts[in], ", ")))
+		}
+	}
+	if len(members) > 0 {
+		sb.WriteString(" " + strings.Join(members, ", ") + " ")
+	}
+	sb.WriteString("}, init);\n}\n")
+	return sb.String()
+}
+
+// tsAccess returns the member access of the key
+func tsAccess(key string) string {
+	if k := tsKey(key); k != key {
+		return "[" + k + "]"
+	}
+	return "." + key
+}
+
+// tsRuntime is the part of the client shared by the functions. Body
+// fields are sent as JSON, form fields as a form unless the route also
+// has body fields in which case they are sent in the query which inject
+// reads them from when the body is not a form
+const tsRuntime = `export interface ClientConfig {
+  baseURL: string;
+  headers?: Record<string, string>;
+  fetch?: typeof fetch;
+}
+
+export class RubikError extends Error {
+  constructor(public status: number, public body: unknown) {
+    super(typeof body === "string" && body !== "" ? body : "request failed with status " + status);
+    this.name = "RubikError";
+  }
+}
+
+interface RequestParts {
+  query?: Record<string, unknown>;
+  body?: Record<string, unknown>;
+  form?: Record<string, unknown>;
+}
+
+function isBlob(v: unknown): v is Blob {
+  return typeof Blob !== "undefined" && v instanceof Blob;
+}
+
+function defined(values: Record<string, unknown> = {}): [string, unknown][] {
+  return Object.entries(values).filter(([, v]) => v !== undefined && v !== null);
+}
+
+async function request<T>(config: ClientConfig, method: string, path: string,
+  parts: RequestParts, init: RequestInit = {}): Promise<T> {
+  const url = new URL(config.baseURL.replace(/\/+$/, "") + path);
+  const headers: Record<string, string> = { ...config.headers };
+  let body: BodyInit | undefined;
+
+  let query = defined(parts.query);
+  const form = defined(parts.form);
+  if (parts.body !== undefined) {
+    headers["Content-Type"] = "application/json";
+    body = JSON.stringify(parts.body);
+    query = query.concat(form);
+  } else if (form.some(([, v]) => isBlob(v))) {
+    const data = new FormData();
+    for (const [k, v] of form) {
+      data.append(k, isBlob(v) ? v : String(v));
+    }
+    body = data;
+  } else if (form.length > 0) {
+    headers["Content-Type"] = "application/x-www-form-urlencoded";
+    body = new URLSearchParams(form.map(([k, v]) => [k, String(v)])).toString();
+  }
+
+  for (const [k, v] of query) {
+    url.searchParams.set(k, String(v));
+  }
+
+  const res = await (config.fetch ?? fetch)(url.toString(), {
+    ...init,
+    method,
+    headers: { ...headers, ...(init.headers as Record<string, string> | undefined) },
+    body,
+  });
+
+  const text = await res.text();
+  let data: unknown = text;
+  if ((res.headers.get("Content-Type") ?? "").includes("application/json") && text !== "") {
+    data = JSON.parse(text);
+  }
+
+  if (!res.ok) {
+    throw new RubikError(res.status, data);
+  }
+  return data as T;
+}
+`
+
+// GenerateTypeScript returns the source of a TypeScript module with the
+// interfaces of the entities and responses of your routes and one fetch
+// based function per route
+func GenerateTypeScript(desc APIDescription, opts TypeScriptOptions) []byte {
+	tt := &tsTypes{
+		names:    make(map[reflect.Type]string),
+		entities: make(map[reflect.Type]string),
+		taken: map[string]bool{
+			"ClientConfig": true, "RubikError": true, "RequestParts": true,
+		},
+	}
+
+	var routes []APIRoute
+	for _, r := range desc.Routes {
+		if opts.All || r.Export {
+			routes = append(routes, r)
+		}
+	}
+
+	var functions []string
+	names := make(map[string]bool)
+	for _, r := range routes {
+		name := operationID(r.Method, r.Path)
+		for base, i := name, 2; names[name]; i++ {
+			name = base + strconv.Itoa(i)
+		}
+		names[name] = true
+		functions = append(functions, tt.tsFunction(r, name))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("// Code generated by rubik. DO NOT EDIT.\n")
+	if desc.Service != "" {
+		fmt.Fprintf(&sb, "// Client of the %s service.\n", desc.Service)
+	}
+	sb.WriteString("\n" + tsRuntime)
+	for _, d := range tt.decls {
+		sb.WriteString("\n" + d)
+	}
+	for _, f := range functions {
+		sb.WriteString("\n" + f)
+	}
+	return []byte(sb.String())
+}
+
+// TypeScriptPlugin generates the TypeScript client of your exported routes
+//
+// 		RUBIK_ENV=plugin RUBIK_PLUGIN=typescript RUBIK_ARGS="-out web/src/api.ts" go run .
+type TypeScriptPlugin struct {
+	file string
+	all  bool
+	out  io.Writer
+}
+
+// OnPlug implements the Plugin interface
+func (tp *TypeScriptPlugin) OnPlug(app *App) error {
+	src := GenerateTypeScript(describeAPI(app.RouteTree), TypeScriptOptions{All: tp.all})
+	if tp.file == "" || tp.file == "-" {
+		_, err := pluginOutput(tp.out).Write(src)
+		return err
+	}
+	return ioutil.WriteFile(tp.file, src, 0644)
+}
+
+// Flags implements the FlagPlugin interface
+func (tp *TypeScriptPlugin) Flags(fs *flag.FlagSet) {
+	fs.StringVar(&tp.file, "out", "-", "file to write the client to, - for stdout")
+	fs.BoolVar(&tp.all, "all", false, "generate functions for the routes without Export")
+}
+
+// Name implements the Plugin interface
+func (tp *TypeScriptPlugin) Name() string { return "Rubik TypeScript Generator" }
+
+// RunID implements the Plugin interface
+func (tp *TypeScriptPlugin) RunID() string { return "typescript" }
+
+// Description implements the DescribedPlugin interface
+func (tp *TypeScriptPlugin) Description() string {
+	return "generates the TypeScript client of the exported routes"
+}
@@ -0,0 +1,99 @@
+package rubik
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tsUser struct {
+	ID      int       `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Tags    []string  `json:"tags"`
+	Created time.Time `json:"created"`
+	Friend  *tsUser   `json:"friend"`
+	secret  string
+}
+
+type tsUpload struct {
+	Entity
+	Avatar File   `rubik:"avatar|form!"`
+	Note   string `rubik:"note|form"`
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	desc := describeAPI(testRouteTree())
+	for i := range desc.Routes {
+		desc.Routes[i].Export = desc.Routes[i].Path != "/"
+	}
+	desc.Routes[1].ResponseTypes = map[int]reflect.Type{200: reflect.TypeOf(tsUser{})}
+	desc.Routes = append(desc.Routes, APIRoute{Method: "POST", Path: "/upload", Export: true,
+		Entity: "tsUpload", EntityType: reflect.TypeOf(tsUpload{}), Fields: entityFields(tsUpload{})})
+
+	code := string(GenerateTypeScript(desc, TypeScriptOptions{}))
+	for _, want := range []string{
+		"export interface tsUser {\n  id: number;\n  name?: string;\n  tags: string[];\n" +
+			"  created: string;\n  friend?: tsUser;\n}",
+		"export interface describeEn {\n  id: number;\n  name?: string;\n  limit?: number;\n}",
+		"export function getUsersId(config: ClientConfig, id: string | number, " +
+			"init?: RequestInit): Promise<tsUser>",
+		"return request<tsUser>(config, \"GET\", `/users/${encodeURIComponent(String(id))}`, {}, init);",
+		"export function postUsersId(config: ClientConfig, en: describeEn, init?: RequestInit)",
+		"`/users/${encodeURIComponent(String(en.id))}`, { query: { limit: en.limit }, " +
+			"body: { name: en.name } }, init);",
+		"export interface tsUpload {\n  avatar: Blob;\n  note?: string;\n}",
+		"{ form: { avatar: en.avatar, note: en.note } }",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("GenerateTypeScript() output does not contain %s\n%s", want, code)
+		}
+	}
+
+	if strings.Contains(code, "secret") || strings.Contains(code, "function get(") {
+		t.Error("GenerateTypeScript() generated unexported fields or routes")
+	}
+}
+
+type tsProfile struct {
+	Entity
+	Name  string  `rubik:"name|body"`
+	Age   int     `rubik:"age|body"`
+	Ok    bool    `rubik:"ok|body"`
+	Score float64 `rubik:"score|body"`
+}
+
+func TestTypeScriptRoundTrip(t *testing.T) {
+	desc := APIDescription{Routes: []APIRoute{{Method: "POST", Path: "/profile", Export: true,
+		Entity: "tsProfile", EntityType: reflect.TypeOf(tsProfile{}),
+		Fields: entityFields(tsProfile{})}}}
+
+	code := string(GenerateTypeScript(desc, TypeScriptOptions{}))
+	for _, want := range []string{
+		"  age?: number;\n  ok?: boolean;\n  score?: number;",
+		"{ body: { name: en.name, age: en.age, ok: en.ok, score: en.score } }",
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("GenerateTypeScript() output does not contain %s\n%s", want, code)
+		}
+	}
+
+	// the body the client sends with JSON.stringify keeps numbers and
+	// booleans as they are
+	b, _ := json.Marshal(map[string]interface{}{"name": "rubik", "age": 42, "ok": true,
+		"score": 9.5})
+	req := httptest.NewRequest("POST", "/profile", bytes.NewReader(b))
+	req.Header.Set(Content.Header, Content.JSON)
+	en, err := inject(req, nil, &tsProfile{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := en.(*tsProfile)
+	if p.Name != "rubik" || p.Age != 42 || !p.Ok || p.Score != 9.5 {
+		t.Errorf("inject() did not read the JSON values sent by the client: %+v", p)
+	}
+}
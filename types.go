@@ -24,7 +24,10 @@ var Type = struct {
 	templateHTML ByteType
 	templateText ByteType
 	Gob          ByteType
-}{1, 2, 3, 4, 5, 6, 7}
+	XML          ByteType
+	MsgPack      ByteType
+	Auto         ByteType
+}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
 
 // Content is a struct that holds default values of Content-Type headers
 // it can be used throughout your rubik application for avoiding basic
@@ -36,6 +39,10 @@ var Content = struct {
 	HTML       string
 	URLEncoded string
 	Multipart  string
+	XML        string
+	MsgPack    string
+	Bytes      string
+	Gob        string
 }{
 	"Content-Type",
 	"application/json",
@@ -43,12 +50,21 @@ var Content = struct {
 	"text/html",
 	"application/x-www-form-urlencoded",
 	"multipart/form-data",
+	"application/xml",
+	"application/msgpack",
+	"application/octet-stream",
+	"application/x-gob",
 }
 
 var StringByteTypeMap = map[string]ByteType{
-	"json": Type.JSON,
-	"html": Type.HTML,
-	"text": Type.Text,
+	"json":    Type.JSON,
+	"html":    Type.HTML,
+	"text":    Type.Text,
+	"xml":     Type.XML,
+	"msgpack": Type.MsgPack,
+	"auto":    Type.Auto,
+	"bytes":   Type.Bytes,
+	"gob":     Type.Gob,
 }
 
 // Message that is to be sent in communicator channel
@@ -104,6 +120,7 @@ type Entity struct {
 	FormData   bool
 	URLEncoded bool
 	JSON       bool
+	Gob        bool
 	Infer      interface{}
 	Cookies    Values
 	RawBody    []byte
@@ -167,14 +184,26 @@ type File struct {
 // and it's methods to provide additional functionalities related to Rubik.
 type RResponseWriter struct {
 	http.ResponseWriter
-	written bool
-	status  int
-	data    []byte
+	written      bool
+	wroteHeader  bool
+	status       int
+	size         int
+	data         []byte
+	beforeHeader func()
 }
 
 // WriteHeader writes the http.Request's Header values to the wire and
-// sets the status given as the parameter
+// sets the status given as the parameter. Only the first call to
+// WriteHeader is written to the wire, every other call is ignored
 func (w *RResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.beforeHeader != nil {
+		w.beforeHeader()
+	}
+
 	w.status = status
 	w.written = true
 	w.ResponseWriter.WriteHeader(status)
@@ -182,9 +211,15 @@ func (w *RResponseWriter) WriteHeader(status int) {
 
 // Write writes the response bytes b to the wire
 func (w *RResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
 	w.data = b
 	w.written = true
-	return w.ResponseWriter.Write(b)
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
 }
 
 // Assertion is the assert functions for rubik's validation cycle